@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hzionn/prompt-manager-cli/internal/config"
+)
+
+// runConfig implements `pm config`. It resolves the settings file itself so
+// that path, init and set keep working when the file does not parse.
+func runConfig(configFlag string, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("config requires a subcommand (show, get, set, path or init)")
+	}
+
+	switch args[0] {
+	case "show":
+		return runConfigShow(configFlag, out)
+	case "get":
+		return runConfigGet(configFlag, args[1:], out)
+	case "set":
+		return runConfigSet(configFlag, args[1:], out)
+	case "path":
+		path, _ := resolveConfigPath(configFlag)
+		_, err := fmt.Fprintln(out, path)
+		return err
+	case "init":
+		return runConfigInit(configFlag, args[1:], out)
+	default:
+		return fmt.Errorf("unknown config subcommand %q (expected show, get, set, path or init)", args[0])
+	}
+}
+
+// runConfigShow prints every effective setting as TOML, each followed by
+// where its value comes from: a settings file, a PM_* variable or the
+// defaults.
+func runConfigShow(configFlag string, out io.Writer) error {
+	ctx, err := newAppContext(configFlag)
+	if err != nil {
+		return err
+	}
+	origins, err := newConfigOrigins(ctx)
+	if err != nil {
+		return err
+	}
+	settings := ctx.settings
+
+	for _, key := range config.Keys() {
+		value, _ := config.Value(settings, key.Name)
+		fmt.Fprintf(out, "%s = %s  # %s\n", key.Name, config.FormatValue(value), origins.of(key.Name, key.Env()))
+	}
+
+	names := make([]string, 0, len(settings.Targets))
+	for name := range settings.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := reflect.TypeOf(config.TargetSettings{})
+	for _, name := range names {
+		for i := 0; i < fields.NumField(); i++ {
+			field := fields.Field(i).Tag.Get("toml")
+			key := "targets." + name + "." + field
+			value, _ := config.Value(settings, key)
+			if reflect.ValueOf(value).IsZero() {
+				continue
+			}
+			env := config.EnvPrefix + "TARGETS_" + strings.ToUpper(name) + "_" + strings.ToUpper(field)
+			fmt.Fprintf(out, "%s = %s  # %s\n", key, config.FormatValue(value), origins.of(key, env))
+		}
+	}
+
+	index := 0
+	for _, source := range settings.ProjectSources {
+		origin := ctx.project.ConfigPath
+		if source.Path == ctx.project.Dir {
+			origin = ctx.project.Dir
+		}
+		fmt.Fprintf(out, "sources[%d] = %s  # %s\n", index, config.FormatValue(source), origin)
+		index++
+	}
+	for _, source := range settings.Sources {
+		fmt.Fprintf(out, "sources[%d] = %s  # %s\n", index, config.FormatValue(source), ctx.configPath)
+		index++
+	}
+
+	globalPacks := len(origins[1].settings.Packs)
+	for i, pack := range settings.Packs {
+		origin := ctx.configPath
+		if i >= globalPacks {
+			origin = ctx.project.ConfigPath
+		}
+		fmt.Fprintf(out, "packs[%d] = %s  # %s\n", i, config.FormatValue(pack), origin)
+	}
+	return nil
+}
+
+// configOrigins tells which configuration layer set a key: the last one that
+// changed its value.
+type configOrigins []settingsLayer
+
+func newConfigOrigins(ctx appContext) (configOrigins, error) {
+	layers, _, err := loadSettings(ctx.configPath)
+	return configOrigins(layers), err
+}
+
+func (o configOrigins) of(key, env string) string {
+	origin := "default"
+	for i := 1; i < len(o); i++ {
+		before, _ := config.Value(o[i-1].settings, key)
+		after, _ := config.Value(o[i].settings, key)
+		if config.FormatValue(before) == config.FormatValue(after) {
+			continue
+		}
+		origin = o[i].origin
+		if origin == "" {
+			origin = "$" + env
+		}
+	}
+	return origin
+}
+
+// runConfigGet prints the effective value of one setting, with lists comma
+// separated and tables as key=value pairs, the way `pm config set` and PM_*
+// variables take them.
+func runConfigGet(configFlag string, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("config get requires a key, such as fuzzy_search.max_results")
+	}
+	ctx, err := newAppContext(configFlag)
+	if err != nil {
+		return err
+	}
+	value, ok := config.Value(ctx.settings, args[0])
+	if !ok {
+		return fmt.Errorf("unknown setting %q; see `pm config show`", args[0])
+	}
+
+	switch v := value.(type) {
+	case []string:
+		value = strings.Join(v, ",")
+	case map[string]string:
+		pairs := make([]string, 0, len(v))
+		for key, item := range v {
+			pairs = append(pairs, key+"="+item)
+		}
+		sort.Strings(pairs)
+		value = strings.Join(pairs, ",")
+	}
+	_, err = fmt.Fprintln(out, value)
+	return err
+}
+
+// runConfigSet writes one setting to the settings file, keeping its comments.
+func runConfigSet(configFlag string, args []string, out io.Writer) error {
+	if len(args) != 2 {
+		return errors.New("config set requires a key and a value")
+	}
+	path, _ := resolveConfigPath(configFlag)
+	if err := config.SetFileValue(path, args[0], args[1]); err != nil {
+		return err
+	}
+	value, err := config.ParseValue(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s = %s in %s\n", args[0], config.FormatValue(value), path)
+	return err
+}
+
+// runConfigInit writes the commented starter settings file.
+func runConfigInit(configFlag string, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("config init", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var force bool
+	fs.BoolVar(&force, "force", false, "Overwrite an existing settings file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path, _ := resolveConfigPath(configFlag)
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, config.Starter, 0o644); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "wrote %s\n", path)
+	return err
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/config"
+)
+
+func TestRunConfigShowReportsOrigins(t *testing.T) {
+	t.Chdir(t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "settings.toml")
+	content := `cache_dir = "~/.cache/pm"
+
+[targets.claude]
+command = "claude"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("PM_CHAT_MODEL", "llama3")
+
+	var out bytes.Buffer
+	if err := run([]string{"--config", configPath, "config", "show"}, nil, &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+
+	for _, want := range []string{
+		`cache_dir = "~/.cache/pm"  # ` + configPath + "\n",
+		`chat.model = "llama3"  # $PM_CHAT_MODEL` + "\n",
+		`fuzzy_search.max_results = 20  # default` + "\n",
+		`targets.claude.command = "claude"  # ` + configPath + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunConfigShowReportsTheLayerThatChangedEachKey(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	project := "[ui]\ntruncate_length = 60\n\n[clipboard]\ncommand = \"./copy\"\n"
+	if err := os.WriteFile(filepath.Join(root, config.ProjectFile), []byte(project), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	configPath := filepath.Join(t.TempDir(), "settings.toml")
+	content := "[fuzzy_search]\nmax_results = 20\n\n[ui]\ntruncate_length = 80\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"--config", configPath, "config", "show"}, nil, &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+
+	for _, want := range []string{
+		`fuzzy_search.max_results = 20  # default` + "\n",
+		`ui.truncate_length = 60  # ` + filepath.Join(root, config.ProjectFile) + "\n",
+		`clipboard.command = ""  # default` + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunConfigGetSetAndPath(t *testing.T) {
+	t.Chdir(t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "settings.toml")
+	t.Setenv(config.ConfigEnv, configPath)
+
+	var out bytes.Buffer
+	if err := run([]string{"config", "path"}, nil, &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+	if out.String() != configPath+"\n" {
+		t.Fatalf("expected the PM_CONFIG path, got %q", out.String())
+	}
+
+	if err := run([]string{"config", "init"}, nil, &out); err != nil {
+		t.Fatalf("config init error = %v", err)
+	}
+	if err := run([]string{"config", "init"}, nil, &out); err == nil {
+		t.Fatal("expected init to refuse to overwrite the settings file")
+	}
+
+	for _, args := range [][]string{
+		{"config", "set", "file_system.extensions", ".md,.prompt"},
+		{"config", "set", "chat.model", "llama3"},
+	} {
+		if err := run(args, nil, &out); err != nil {
+			t.Fatalf("%v error = %v", args, err)
+		}
+	}
+	if err := run([]string{"config", "set", "chat.model"}, nil, &out); err == nil {
+		t.Fatal("expected set without a value to fail")
+	}
+	if err := run([]string{"config", "set", "ui.truncate_length", "wide"}, nil, &out); err == nil {
+		t.Fatal("expected set with an invalid number to fail")
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "# model = \"gpt-4o-mini\"\nmodel = \"llama3\"\n") ||
+		!strings.Contains(string(data), "extensions = [\".md\", \".prompt\"]\n") {
+		t.Fatalf("expected an in-place edit keeping comments, got:\n%s", data)
+	}
+
+	for key, want := range map[string]string{
+		"file_system.extensions":   ".md,.prompt\n",
+		"chat.model":               "llama3\n",
+		"fuzzy_search.max_results": "20\n",
+	} {
+		out.Reset()
+		if err := run([]string{"config", "get", key}, nil, &out); err != nil {
+			t.Fatalf("config get %s error = %v", key, err)
+		}
+		if out.String() != want {
+			t.Errorf("config get %s = %q, want %q", key, out.String(), want)
+		}
+	}
+	if err := run([]string{"config", "get", "nope"}, nil, &out); err == nil {
+		t.Fatal("expected unknown keys to fail")
+	}
+}
+
+func TestRunConfigPathWorksWithMalformedFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.toml")
+	if err := os.WriteFile(configPath, []byte("[ui\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"--config", configPath, "config", "path"}, nil, &out); err != nil {
+		t.Fatalf("config path error = %v", err)
+	}
+	if err := run([]string{"--config", configPath, "config", "show"}, nil, &out); err == nil {
+		t.Fatal("expected show to report the malformed file")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+// diagnosticsOutput receives the warning printed when prompts were skipped.
+var diagnosticsOutput io.Writer = os.Stderr
+
+func warnDiagnostics(diagnostics []prompt.Diagnostic) {
+	if len(diagnostics) == 0 {
+		return
+	}
+	noun := "files"
+	if len(diagnostics) == 1 {
+		noun = "file"
+	}
+	fmt.Fprintf(diagnosticsOutput, "pm: skipped %d %s that could not be loaded (run `pm doctor` for details)\n", len(diagnostics), noun)
+}
+
+func runDoctor(ctx appContext, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var dirFlag string
+	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	configState := "found"
+	if _, err := os.Stat(ctx.configPath); err != nil {
+		configState = "not found, using defaults"
+	}
+	fmt.Fprintf(out, "config: %s (%s)\n", ctx.configPath, configState)
+	if ctx.project.ConfigPath != "" {
+		fmt.Fprintf(out, "project config: %s\n", ctx.project.ConfigPath)
+	}
+
+	dirs, opts := resolveDirs(ctx, dirFlag)
+	fmt.Fprintln(out, "prompt dirs:")
+	if len(dirs) == 0 {
+		fmt.Fprintln(out, "  (none configured)")
+	}
+	for _, dir := range dirs {
+		state := "ok"
+		if info, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			state = "missing"
+		} else if err != nil {
+			state = err.Error()
+		} else if !info.IsDir() {
+			state = "not a directory"
+		}
+		if source := opts.Sources[dir]; source.Label != "" {
+			if state == "missing" && strings.HasPrefix(dir, cacheDir(ctx)) {
+				state = "not synced, run `pm sync`"
+			}
+			state = source.Label + ", " + state
+		}
+		fmt.Fprintf(out, "  %s (%s)\n", dir, state)
+	}
+
+	prompts, diagnostics, err := prompt.LoadContext(context.Background(), dirs, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "prompts: %d loaded, %d skipped\n", len(prompts), len(diagnostics))
+	for _, d := range diagnostics {
+		fmt.Fprintf(out, "  %v\n", d)
+	}
+
+	return writeClipboardReport(ctx, out)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctorReportsDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "good.md"), []byte("Good prompt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.md"), []byte("\xff\xfe"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := testAppContext()
+	ctx.configPath = filepath.Join(dir, "missing.toml")
+	ctx.settings.DefaultDirs = []string{dir, filepath.Join(dir, "nowhere")}
+
+	var out bytes.Buffer
+	if err := runDoctor(ctx, nil, &out); err != nil {
+		t.Fatalf("runDoctor error = %v", err)
+	}
+
+	report := out.String()
+	for _, want := range []string{
+		"config: " + ctx.configPath + " (not found, using defaults)",
+		dir + " (ok)",
+		filepath.Join(dir, "nowhere") + " (missing)",
+		"prompts: 1 loaded, 1 skipped",
+		filepath.Join(dir, "bad.md") + ": file is not valid UTF-8",
+		"clipboard: provider",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q in report, got:\n%s", want, report)
+		}
+	}
+}
+
+func TestLoadPromptsWarnsAboutSkippedFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "good.md"), []byte("Good prompt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.md"), []byte("\xff\xfe"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings bytes.Buffer
+	diagnosticsOutput = &warnings
+	defer func() { diagnosticsOutput = os.Stderr }()
+
+	prompts, err := loadPrompts(testAppContext(), dir)
+	if err != nil {
+		t.Fatalf("loadPrompts error = %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "good" {
+		t.Fatalf("expected only the good prompt, got %+v", prompts)
+	}
+	if !strings.Contains(warnings.String(), "skipped 1 file that could not be loaded") {
+		t.Fatalf("expected a skipped-file warning, got %q", warnings.String())
+	}
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// execProcess replaces the current process image, optionally wiring stdin to
+// the given file first.
+func execProcess(path string, argv []string, stdin *os.File) error {
+	if stdin != nil {
+		if err := unix.Dup2(int(stdin.Fd()), int(os.Stdin.Fd())); err != nil {
+			return err
+		}
+	}
+	return syscall.Exec(path, argv, os.Environ())
+}
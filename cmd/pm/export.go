@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hzionn/prompt-manager-cli/internal/exporter"
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+	"github.com/hzionn/prompt-manager-cli/internal/search"
+)
+
+// runExport implements `pm export`: it writes the library, or the prompts
+// matching --query and --tag, as a single bundle.
+func runExport(ctx appContext, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var dirFlag, format, output, query, tag, title string
+	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	fs.StringVar(&format, "format", "", "Bundle format: "+strings.Join(exporter.Formats(), ", "))
+	fs.StringVar(&output, "output", "", "Write to this file instead of stdout")
+	fs.StringVar(&output, "o", "", "Shorthand for --output")
+	fs.StringVar(&query, "query", "", "Only export prompts matching this search query")
+	fs.StringVar(&tag, "tag", "", "Only export prompts with this tag or one of its subtags")
+	fs.StringVar(&title, "title", "", "Title of the Markdown handbook")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if format == "" {
+		guessed, ok := exporter.FormatFor(output)
+		if output != "" && !ok {
+			return fmt.Errorf("cannot tell the format of %s; use --format (%s)", output, strings.Join(exporter.Formats(), ", "))
+		}
+		format = guessed
+		if format == "" {
+			format = exporter.FormatJSON
+		}
+	}
+
+	prompts, err := loadPrompts(ctx, dirFlag)
+	if err != nil {
+		return err
+	}
+
+	var selected []prompt.Prompt
+	for _, p := range search.Search(prompts, query, search.Options{}) {
+		if tag != "" && !p.HasTag(tag) {
+			continue
+		}
+		selected = append(selected, p)
+	}
+
+	opts := exporter.Options{Dirs: promptDirs(ctx, dirFlag), Title: title}
+	if output == "" {
+		return exporter.Write(out, format, selected, opts)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	err = exporter.Write(file, format, selected, opts)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(output))
+	}
+	_, err = fmt.Fprintf(out, "Exported %d prompts to %s\n", len(selected), output)
+	return err
+}
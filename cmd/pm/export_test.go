@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+func TestRunExportFiltersByTag(t *testing.T) {
+	var out bytes.Buffer
+	if err := runExport(testAppContext(), []string{"--tag", "launch"}, &out); err != nil {
+		t.Fatalf("runExport error = %v", err)
+	}
+
+	var prompts []prompt.Prompt
+	if err := json.Unmarshal(out.Bytes(), &prompts); err != nil {
+		t.Fatalf("decode export %q: %v", out.String(), err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "product-brief" {
+		t.Fatalf("expected only product-brief, got %+v", prompts)
+	}
+}
+
+func TestRunExportWritesFileInGuessedFormat(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "handbook.md")
+	var out bytes.Buffer
+	if err := runExport(testAppContext(), []string{"--query", "code review", "-o", output}, &out); err != nil {
+		t.Fatalf("runExport error = %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read handbook: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# Prompt Handbook\n") || !strings.Contains(string(data), "## code-review\n") {
+		t.Fatalf("unexpected handbook %q", data)
+	}
+	if !strings.HasPrefix(out.String(), "Exported ") {
+		t.Fatalf("expected a summary, got %q", out.String())
+	}
+
+	if err := runExport(testAppContext(), []string{"-o", filepath.Join(t.TempDir(), "pack.zip")}, &out); err == nil {
+		t.Fatal("expected an error for an unknown output extension")
+	}
+}
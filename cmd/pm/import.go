@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hzionn/prompt-manager-cli/internal/importer"
+)
+
+// runImport implements `pm import`: it converts another tool's export into
+// Markdown prompts in the first writable prompt directory.
+func runImport(ctx appContext, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var dirFlag string
+	var format string
+	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated); prompts are written to the first writable one")
+	fs.StringVar(&format, "from", "", "Export format: "+strings.Join(importer.Formats(), ", "))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if format == "" {
+		return fmt.Errorf("import requires --from (%s)", strings.Join(importer.Formats(), ", "))
+	}
+	if fs.NArg() != 1 {
+		return errors.New("import requires exactly one file to import")
+	}
+
+	dirs, opts := resolveDirs(ctx, dirFlag)
+	target := ""
+	for _, dir := range dirs {
+		if !opts.Sources[dir].ReadOnly {
+			target = dir
+			break
+		}
+	}
+	if target == "" {
+		return errors.New("no writable prompt directory configured to import into")
+	}
+
+	entries, skipped, err := importer.Read(format, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	prompts, err := loadPrompts(ctx, dirFlag)
+	if err != nil {
+		return err
+	}
+	existing := make([]string, 0, len(prompts))
+	for _, p := range prompts {
+		existing = append(existing, p.Name)
+	}
+
+	result, err := importer.Write(target, entries, existing)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Imported %d of %d prompts into %s\n", len(result.Written), len(entries)+len(skipped), target)
+	for _, collision := range result.Collisions {
+		if collision.Path != "" {
+			fmt.Fprintf(out, "collision: %s (%s already exists)\n", collision.Name, collision.Path)
+		} else {
+			fmt.Fprintf(out, "collision: %s (name already in use)\n", collision.Name)
+		}
+	}
+	for _, skip := range skipped {
+		fmt.Fprintf(out, "skipped: %s (%s)\n", skip.Name, skip.Reason)
+	}
+	return nil
+}
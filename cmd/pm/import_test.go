@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunImportWritesPromptsAndReportsProblems(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing.md"), []byte("Existing\n"), 0o644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+	export := filepath.Join(t.TempDir(), "commands.json")
+	commands := `[{"title": "Explain Code", "prompt": "Explain {selection}"}, {"title": "Existing", "prompt": "x"}, {"title": "Empty", "prompt": ""}]`
+	if err := os.WriteFile(export, []byte(commands), 0o644); err != nil {
+		t.Fatalf("write export: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runImport(testAppContext(), []string{"--from", "raycast", "--dir", dir, export}, &out); err != nil {
+		t.Fatalf("runImport error = %v", err)
+	}
+
+	report := out.String()
+	for _, want := range []string{"Imported 1 of 3 prompts into " + dir, "collision: Existing", "skipped: Empty (empty prompt)"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q in report %q", want, report)
+		}
+	}
+	var cat bytes.Buffer
+	if err := runCat(testAppContext(), []string{"--dir", dir, "explain code"}, &cat); err != nil {
+		t.Fatalf("runCat error = %v", err)
+	}
+	if cat.String() != "Explain {{selection}}\n" {
+		t.Fatalf("expected the imported prompt, got %q", cat.String())
+	}
+
+	if err := runImport(testAppContext(), []string{export}, &out); err == nil {
+		t.Fatal("expected an error without --from")
+	}
+}
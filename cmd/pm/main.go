@@ -1,19 +1,25 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 
 	"github.com/hzionn/prompt-manager-cli/internal/clipboard"
 	"github.com/hzionn/prompt-manager-cli/internal/config"
 	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+	"github.com/hzionn/prompt-manager-cli/internal/remote"
 	"github.com/hzionn/prompt-manager-cli/internal/search"
 	"github.com/hzionn/prompt-manager-cli/internal/ui"
 )
@@ -28,30 +34,143 @@ func main() {
 type appContext struct {
 	settings   config.Settings
 	configPath string
+	// project is the project found above the working directory, if any.
+	project    config.Project
 	promptOpts prompt.Options
 	searchOpts search.Options
 }
 
-func newAppContext() appContext {
-	configPath := config.DefaultPath()
-	settings := config.Load(configPath)
+// newAppContext layers the configuration: defaults, the settings file, the
+// project's settings and prompt directories when inside a project, then
+// PM_* environment variables. Command-line flags override all of them.
+func newAppContext(configFlag string) (appContext, error) {
+	configPath, explicit := resolveConfigPath(configFlag)
+	if explicit {
+		if _, err := os.Stat(configPath); err != nil {
+			return appContext{}, fmt.Errorf("config: %w", err)
+		}
+	}
+	layers, project, err := loadSettings(configPath)
+	if err != nil {
+		return appContext{}, err
+	}
+	settings := layers[len(layers)-1].settings
 	maxBytes := int64(settings.FileSystem.MaxFileSizeKB) * 1024
 	return appContext{
-		settings: settings,
+		settings:   settings,
 		configPath: configPath,
+		project:    project,
 		promptOpts: prompt.Options{
 			Extensions:     settings.FileSystem.Extensions,
 			IgnorePatterns: settings.FileSystem.IgnorePatterns,
 			MaxFileSize:    maxBytes,
+			UseGitignore:   settings.FileSystem.UseGitignore,
+			FollowSymlinks: settings.FileSystem.FollowSymlinks,
+			SplitSections:  settings.FileSystem.SplitSections,
+			Discovery: prompt.Discovery{
+				Paths:   settings.Discovery.Paths,
+				Markers: settings.Discovery.Markers,
+				Tags:    settings.Discovery.Tags,
+			},
 		},
 		searchOpts: search.Options{
 			MaxResults: settings.FuzzySearch.MaxResults,
 		},
+	}, nil
+}
+
+// settingsLayer is the settings as they stand after one configuration layer.
+type settingsLayer struct {
+	// origin names the layer: "default", a settings file, or "" for the PM_*
+	// variables.
+	origin   string
+	settings config.Settings
+}
+
+// loadSettings applies the configuration layers in order: the defaults, the
+// settings file at configPath, the current project and the PM_* variables.
+func loadSettings(configPath string) ([]settingsLayer, config.Project, error) {
+	layers := []settingsLayer{{origin: "default", settings: config.Defaults()}}
+	settings, err := config.Load(configPath)
+	if err != nil {
+		return nil, config.Project{}, err
+	}
+	layers = append(layers, settingsLayer{origin: configPath, settings: settings})
+
+	var project config.Project
+	if cwd, err := os.Getwd(); err == nil {
+		if found, ok := config.FindProject(cwd); ok {
+			project = found
+			if settings, err = config.LoadProject(settings, project); err != nil {
+				return nil, project, err
+			}
+			origin := project.ConfigPath
+			if origin == "" {
+				origin = project.Dir
+			}
+			layers = append(layers, settingsLayer{origin: origin, settings: settings})
+		}
+	}
+
+	if settings, err = config.ApplyEnv(settings, os.Environ()); err != nil {
+		return nil, project, err
+	}
+	return append(layers, settingsLayer{settings: settings}), project, nil
+}
+
+// resolveConfigPath picks the settings file: --config, then $PM_CONFIG, then
+// config.DefaultPath. explicit reports whether the user named the file.
+func resolveConfigPath(configFlag string) (path string, explicit bool) {
+	if configFlag != "" {
+		return expandTilde(configFlag), true
+	}
+	if env := os.Getenv(config.ConfigEnv); env != "" {
+		return expandTilde(env), true
+	}
+	return config.DefaultPath(), false
+}
+
+// extractConfigFlag removes the global --config flag, accepted anywhere
+// before "--", from args.
+func extractConfigFlag(args []string) (string, []string, error) {
+	var configFlag string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--config" && name != "-config" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", nil, errors.New("--config requires a path")
+			}
+			i++
+			value = args[i]
+		}
+		configFlag = value
 	}
+	return configFlag, rest, nil
 }
 
 func run(args []string, in io.Reader, out io.Writer) error {
-	ctx := newAppContext()
+	configFlag, args, err := extractConfigFlag(args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == "config" {
+		return runConfig(configFlag, args[1:], out)
+	}
+	ctx, err := newAppContext(configFlag)
+	if err != nil {
+		return err
+	}
+	configureClipboard(ctx.settings.Clipboard)
 	if len(args) == 0 {
 		return runPick(ctx, []string{}, in, out)
 	}
@@ -67,6 +186,20 @@ func run(args []string, in io.Reader, out io.Writer) error {
 		return runCat(ctx, args[1:], out)
 	case "mesh":
 		return runMesh(ctx, args[1:], in, out)
+	case "run":
+		return runChat(ctx, args[1:], in, out)
+	case "serve":
+		return runServe(ctx, args[1:], in, out)
+	case "clipboard":
+		return runClipboard(ctx, args[1:], in, out)
+	case "doctor":
+		return runDoctor(ctx, args[1:], out)
+	case "import":
+		return runImport(ctx, args[1:], out)
+	case "export":
+		return runExport(ctx, args[1:], out)
+	case "sync":
+		return runSync(ctx, args[1:], out)
 	case "completion":
 		return runCompletion(args[1:], out)
 	case "--help", "-h", "help":
@@ -77,7 +210,7 @@ func run(args []string, in io.Reader, out io.Writer) error {
 			return runPick(ctx, args, in, out)
 		}
 		query := strings.Join(args, " ")
-		return runPickWithQuery(ctx, query, "", false, out)
+		return runPickWithQuery(ctx, query, "", outputOptions{}, out)
 	}
 }
 
@@ -88,35 +221,53 @@ func runPick(ctx appContext, args []string, in io.Reader, out io.Writer) error {
 	var dirFlag string
 	var query string
 	var interactive bool
-	var copyToClipboard bool
+	var output outputOptions
+	var copyTTL int
+	var targetName string
 
 	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
 	fs.StringVar(&query, "query", "", "Query to select a prompt non-interactively")
 	fs.BoolVar(&interactive, "interactive", false, "Force interactive selection")
-	fs.BoolVar(&copyToClipboard, "copy", false, "Copy the chosen prompt to the clipboard")
+	fs.BoolVar(&output.copy, "copy", false, "Copy the chosen prompt to the clipboard")
+	fs.IntVar(&copyTTL, "copy-ttl", 0, "Restore the previous clipboard contents after N seconds")
+	fs.StringVar(&targetName, "to", "", "Hand the chosen prompt to a configured target")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	if copyTTL < 0 {
+		return errors.New("--copy-ttl must not be negative")
+	}
+	output.copyTTL = time.Duration(copyTTL) * time.Second
+	output.configPath = ctx.configPath
+
+	if targetName != "" {
+		target, err := resolveTarget(ctx.settings, targetName)
+		if err != nil {
+			return err
+		}
+		output.target, output.send = target, true
+	}
+
 	if query != "" && interactive {
 		return errors.New("cannot use --query and --interactive together")
 	}
 
 	if query != "" {
-		return runPickWithQuery(ctx, query, dirFlag, copyToClipboard, out)
+		return runPickWithQuery(ctx, query, dirFlag, output, out)
 	}
 
 	if !interactive && fs.NArg() > 0 {
 		// Allow positional query arguments.
 		query = strings.Join(fs.Args(), " ")
-		return runPickWithQuery(ctx, query, dirFlag, copyToClipboard, out)
+		return runPickWithQuery(ctx, query, dirFlag, output, out)
 	}
 
-	return runPickInteractive(ctx, dirFlag, copyToClipboard, in, out)
+	return runPickInteractive(ctx, dirFlag, output, in, out)
 }
 
-func runPickWithQuery(ctx appContext, query, dirFlag string, copyToClipboard bool, out io.Writer) error {
+func runPickWithQuery(ctx appContext, query, dirFlag string, output outputOptions, out io.Writer) error {
 	prompts, err := loadPrompts(ctx, dirFlag)
 	if err != nil {
 		return err
@@ -127,27 +278,43 @@ func runPickWithQuery(ctx appContext, query, dirFlag string, copyToClipboard boo
 		return fmt.Errorf("no prompts found for query %q; prompt dirs: %s; config: %s", query, formatPromptDirs(ctx, dirFlag), ctx.configPath)
 	}
 
-	return outputPrompt(results[0].Content, copyToClipboard, out)
+	return outputPrompt(results[0].Content, output, out)
 }
 
-func runPickInteractive(ctx appContext, dirFlag string, copyToClipboard bool, in io.Reader, out io.Writer) error {
-	prompts, err := loadPrompts(ctx, dirFlag)
+func runPickInteractive(ctx appContext, dirFlag string, output outputOptions, in io.Reader, out io.Writer) error {
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	lib, updates, err := watchPrompts(watchCtx, ctx, dirFlag)
 	if err != nil {
 		return err
 	}
+	prompts := lib.Prompts()
 
 	if len(prompts) == 0 {
 		return fmt.Errorf("no prompts available; prompt dirs: %s; config: %s", formatPromptDirs(ctx, dirFlag), ctx.configPath)
 	}
 
+	uiOpts := ui.Options{TruncateLength: ctx.settings.UI.TruncateLength, Updates: updates}
+	if output.target != nil {
+		uiOpts.TargetLabel = output.target.name
+	} else {
+		uiOpts.TargetLabel = ctx.settings.DefaultTarget
+	}
+
 	sorted := search.Search(prompts, "", search.Options{})
 	// Use stderr for the interactive UI to keep stdout clean for the prompt output
-	selected, err := ui.SelectPromptWithQuery(sorted, "", search.Options{}, ui.Options{TruncateLength: ctx.settings.UI.TruncateLength}, in, os.Stderr)
+	selected, err := ui.Select(sorted, "", search.Options{}, uiOpts, in, os.Stderr)
 	if err != nil {
 		return err
 	}
+	if selected.Action == ui.ActionSend {
+		if output.target, err = keybindingTarget(ctx.settings, output.target); err != nil {
+			return err
+		}
+		output.send = true
+	}
 
-	return outputPrompt(selected.Content, copyToClipboard, out)
+	return outputPrompt(selected.Prompt.Content, output, out)
 }
 
 type fdReader interface {
@@ -220,6 +387,10 @@ func runSearch(ctx appContext, args []string, in io.Reader, out io.Writer) error
 	}
 
 	for _, p := range results {
+		if p.Source != "" {
+			fmt.Fprintf(out, "%s\t%s\t%s\n", p.Name, p.Path, p.Source)
+			continue
+		}
 		fmt.Fprintf(out, "%s\t%s\n", p.Name, p.Path)
 	}
 	return nil
@@ -230,7 +401,9 @@ func runList(ctx appContext, args []string, out io.Writer) error {
 	fs.SetOutput(io.Discard)
 
 	var dirFlag string
+	var tag string
 	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	fs.StringVar(&tag, "tag", "", "Only list prompts with this tag or one of its subtags")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -242,6 +415,13 @@ func runList(ctx appContext, args []string, out io.Writer) error {
 
 	results := search.Search(prompts, "", search.Options{})
 	for _, p := range results {
+		if tag != "" && !p.HasTag(tag) {
+			continue
+		}
+		if p.Source != "" {
+			fmt.Fprintf(out, "%s\t%s\n", p.Name, p.Source)
+			continue
+		}
 		fmt.Fprintln(out, p.Name)
 	}
 	return nil
@@ -252,7 +432,9 @@ func runCat(ctx appContext, args []string, out io.Writer) error {
 	fs.SetOutput(io.Discard)
 
 	var dirFlag string
+	var asJSON bool
 	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	fs.BoolVar(&asJSON, "json", false, "Print the prompt and its metadata as JSON")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -273,6 +455,11 @@ func runCat(ctx appContext, args []string, out io.Writer) error {
 		return err
 	}
 
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(promptItem)
+	}
 	return writePrompt(out, promptItem.Content)
 }
 
@@ -281,7 +468,9 @@ func runMesh(ctx appContext, args []string, in io.Reader, out io.Writer) error {
 	fs.SetOutput(io.Discard)
 
 	var dirFlag string
+	var fromClipboard bool
 	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	fs.BoolVar(&fromClipboard, "from-clipboard", false, "Append the clipboard contents instead of stdin")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -307,6 +496,17 @@ func runMesh(ctx appContext, args []string, in io.Reader, out io.Writer) error {
 		fmt.Fprintln(out)
 	}
 
+	if fromClipboard {
+		extra, err := clipboard.Paste()
+		if err != nil {
+			return fmt.Errorf("read clipboard: %w", err)
+		}
+		if strings.TrimSpace(extra) != "" {
+			return writePrompt(out, extra)
+		}
+		return nil
+	}
+
 	if shouldReadFromInput(in) {
 		if extra, err := io.ReadAll(in); err == nil && len(extra) > 0 {
 			if err := writePrompt(out, string(extra)); err != nil {
@@ -319,19 +519,129 @@ func runMesh(ctx appContext, args []string, in io.Reader, out io.Writer) error {
 }
 
 func loadPrompts(ctx appContext, dirFlag string) ([]prompt.Prompt, error) {
-	dirs := ctx.settings.DefaultDirs
-	if dirFlag != "" {
-		dirs = splitAndTrim(dirFlag)
+	dirs, opts := resolveDirs(ctx, dirFlag)
+	prompts, diagnostics, err := prompt.LoadContext(context.Background(), dirs, opts)
+	if err != nil {
+		return nil, err
 	}
-	return prompt.LoadFromDirs(expandDirs(dirs), ctx.promptOpts)
+	warnDiagnostics(diagnostics)
+	return prompts, nil
 }
 
-func formatPromptDirs(ctx appContext, dirFlag string) string {
-	dirs := ctx.settings.DefaultDirs
-	if dirFlag != "" {
-		dirs = splitAndTrim(dirFlag)
+// watchPrompts loads a library that stays in sync with the prompt directories
+// until watchCtx is done. The returned channel carries the latest snapshot
+// after each change; stale snapshots are dropped if nobody is reading.
+func watchPrompts(watchCtx context.Context, ctx appContext, dirFlag string) (*prompt.Library, <-chan []prompt.Prompt, error) {
+	dirs, opts := resolveDirs(ctx, dirFlag)
+	lib, err := prompt.NewLibrary(dirs, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnDiagnostics(lib.Diagnostics())
+	changes, err := lib.Watch(watchCtx, prompt.WatchOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshots := make(chan []prompt.Prompt, 1)
+	go func() {
+		defer close(snapshots)
+		for range changes {
+			select {
+			case <-snapshots:
+			default:
+			}
+			snapshots <- lib.Prompts()
+		}
+	}()
+	return lib, snapshots, nil
+}
+
+func promptDirs(ctx appContext, dirFlag string) []string {
+	dirs, _ := resolveDirs(ctx, dirFlag)
+	return dirs
+}
+
+// sourceEntries returns the enabled prompt sources in load order, or plain
+// sources for the directories given with --dir.
+func sourceEntries(ctx appContext, dirFlag string) []config.SourceSettings {
+	if dirFlag == "" {
+		return ctx.settings.PromptSources()
+	}
+	var sources []config.SourceSettings
+	for _, dir := range splitAndTrim(dirFlag) {
+		sources = append(sources, config.SourceSettings{Path: dir, Enabled: true})
+	}
+	return sources
+}
+
+// resolveDirs maps the prompt sources to local directories: git sources load
+// from their checkout in cache_dir, read-only and labelled with their origin
+// unless given a label, and other paths have "~" expanded. Configured packs
+// follow unless --dir overrides the directories.
+func resolveDirs(ctx appContext, dirFlag string) ([]string, prompt.Options) {
+	opts := ctx.promptOpts
+	opts.Sources = maps.Clone(opts.Sources)
+	if opts.Sources == nil {
+		opts.Sources = make(map[string]prompt.Source)
+	}
+
+	var dirs []string
+	for _, entry := range sourceEntries(ctx, dirFlag) {
+		source := prompt.Source{
+			Label:          entry.Label,
+			ReadOnly:       entry.ReadOnly,
+			Extensions:     entry.Extensions,
+			IgnorePatterns: entry.IgnorePatterns,
+			MaxFileSize:    int64(entry.MaxFileSizeKB) * 1024,
+		}
+		dir := expandTilde(entry.Path)
+		if repo, ok := remote.ParseGit(entry.Path); ok {
+			dir = repo.Dir(cacheDir(ctx))
+			source.ReadOnly = true
+			if source.Label == "" {
+				source.Label = repo.String()
+			}
+		}
+		if _, ok := opts.Sources[dir]; !ok {
+			opts.Sources[dir] = source
+		}
+		dirs = append(dirs, dir)
+	}
+	if dirFlag == "" {
+		for _, pack := range packs(ctx) {
+			dir := pack.Dir(cacheDir(ctx))
+			opts.Sources[dir] = prompt.Source{Label: pack.Name, ReadOnly: true}
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, opts
+}
+
+func packs(ctx appContext) []remote.Pack {
+	packs := make([]remote.Pack, 0, len(ctx.settings.Packs))
+	for _, settings := range ctx.settings.Packs {
+		packs = append(packs, remote.Pack{
+			Name:         settings.Name,
+			URL:          settings.URL,
+			SHA256:       settings.SHA256,
+			PublicKey:    settings.PublicKey,
+			SignatureURL: settings.SignatureURL,
+		})
 	}
-	dirs = expandDirs(dirs)
+	return packs
+}
+
+// cacheDir returns the directory `pm sync` writes to, never the working
+// directory when cache_dir is set empty.
+func cacheDir(ctx appContext) string {
+	if ctx.settings.CacheDir == "" {
+		return expandTilde(config.Defaults().CacheDir)
+	}
+	return expandTilde(ctx.settings.CacheDir)
+}
+
+func formatPromptDirs(ctx appContext, dirFlag string) string {
+	dirs := promptDirs(ctx, dirFlag)
 	if len(dirs) == 0 {
 		return "(none configured)"
 	}
@@ -379,24 +689,182 @@ func splitAndTrim(input string) []string {
 	return out
 }
 
+// configureClipboard installs the clipboard provider selected in settings. An
+// invalid selection is reported when copying rather than failing every command.
+func configureClipboard(settings config.ClipboardSettings) {
+	if settings.Command != "" {
+		var paste []string
+		if settings.PasteCommand != "" {
+			paste = append([]string{expandTilde(settings.PasteCommand)}, settings.PasteArgs...)
+		}
+		clipboard.SetProvider(clipboard.CommandWithPaste(expandTilde(settings.Command), settings.Args, paste))
+		return
+	}
+	provider, err := clipboard.Named(settings.Provider)
+	if err != nil {
+		clipboard.SetProvider(clipboard.ProviderFunc(func(string) error { return err }))
+		return
+	}
+	clipboard.SetProvider(provider)
+}
+
+func runClipboard(ctx appContext, args []string, in io.Reader, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("clipboard requires a subcommand (doctor or restore)")
+	}
+
+	switch args[0] {
+	case "doctor":
+		return writeClipboardReport(ctx, out)
+	case "restore":
+		return runClipboardRestore(args[1:], in)
+	default:
+		return fmt.Errorf("unknown clipboard subcommand %q (expected doctor or restore)", args[0])
+	}
+}
+
+// clipboardRestore is the payload handed to the background `pm clipboard
+// restore` process started for --copy-ttl.
+type clipboardRestore struct {
+	Previous string `json:"previous"`
+	Copied   string `json:"copied"`
+}
+
+// scheduleClipboardRestore arranges for the clipboard to be restored after ttl.
+// It is a variable so tests can observe the request without spawning processes.
+var scheduleClipboardRestore = spawnClipboardRestore
+
+func spawnClipboardRestore(configPath string, ttl time.Duration, previous, copied string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(clipboardRestore{Previous: previous, Copied: copied})
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(exe, clipboardRestoreArgs(configPath, ttl)...)
+	detachProcess(cmd)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if _, err := stdin.Write(payload); err != nil {
+		stdin.Close()
+		return err
+	}
+	if err := stdin.Close(); err != nil {
+		return err
+	}
+	// The restore process outlives pm; release it instead of waiting.
+	return cmd.Process.Release()
+}
+
+// clipboardRestoreArgs builds the arguments of the restore process. It reads
+// the same settings file as its parent, so that a custom clipboard command is
+// also used to restore; a missing file is left out since --config requires it
+// to exist.
+func clipboardRestoreArgs(configPath string, ttl time.Duration) []string {
+	args := []string{"clipboard", "restore", "--after", ttl.String()}
+	if _, err := os.Stat(configPath); err == nil {
+		args = append([]string{"--config", configPath}, args...)
+	}
+	return args
+}
+
+func runClipboardRestore(args []string, in io.Reader) error {
+	fs := flag.NewFlagSet("clipboard restore", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var after time.Duration
+	fs.DurationVar(&after, "after", 0, "Delay before restoring the clipboard")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var payload clipboardRestore
+	if err := json.NewDecoder(in).Decode(&payload); err != nil {
+		return fmt.Errorf("read restore payload: %w", err)
+	}
+	return clipboard.RestoreAfter(after, payload.Previous, payload.Copied)
+}
+
+func writeClipboardReport(ctx appContext, out io.Writer) error {
+	settings := ctx.settings.Clipboard
+	if settings.Command != "" {
+		status := clipboard.DetectCommand(expandTilde(settings.Command), settings.Args...)
+		fmt.Fprintf(out, "clipboard: command %s\n", strings.Join(append([]string{status.Bin}, status.Args...), " "))
+		if status.Found() {
+			fmt.Fprintf(out, "  found at %s\n", status.Path)
+		} else {
+			fmt.Fprintf(out, "  not found on PATH\n")
+		}
+	} else {
+		name := settings.Provider
+		if name == "" {
+			name = "auto"
+		}
+		fmt.Fprintf(out, "clipboard: provider %s\n", name)
+		if _, err := clipboard.Named(name); err != nil {
+			fmt.Fprintf(out, "  %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(out, "providers:")
+	for _, status := range clipboard.Detect() {
+		marker := " "
+		if status.Auto {
+			marker = "*"
+		}
+		location := "not found"
+		if status.Found() {
+			location = status.Path
+		}
+		fmt.Fprintf(out, "  %s %-10s %s\n", marker, status.Name, location)
+	}
+	_, err := fmt.Fprintln(out, "  (* tried in order when provider is auto)")
+	return err
+}
+
 func printUsage(out io.Writer) {
 	fmt.Fprintln(out, `pm - prompt manager CLI
 
 Usage:
-  pm [--query <query>] [--dir <dir>] [--copy]
-  pm pick [--query <query>] [--interactive] [--copy]
+  pm [--config <file>] <command> ...
+  pm [--query <query>] [--dir <dir>] [--copy] [--copy-ttl N] [--to <target>]
+  pm pick [--query <query>] [--interactive] [--copy] [--copy-ttl N] [--to <target>]
   pm search [--limit N] [--interactive] <query>
-  pm ls
-  pm cat <name>
-  pm mesh <name> [<name>...]
+  pm ls [--tag <tag>]
+  pm cat [--json] <name>
+  pm mesh [--from-clipboard] <name> [<name>...]
+  pm run [--model <model>] <name> [<name>...]
+  pm serve --mcp | --http [--addr <host:port>]
+  pm clipboard doctor
+  pm doctor
+  pm import --from <promptlayer|raycast|espanso|prompty> <file>
+  pm sync
+  pm config show | get <key> | set <key> <value> | path | init [--force]
+  pm export [--format json|jsonl|tar|markdown] [--output <file>] [--query <query>] [--tag <tag>]
   pm completion <bash|zsh|fish>
 
 Flags:
+  --config        Settings file (default $PM_CONFIG, then $XDG_CONFIG_HOME/pmc/settings.toml)
   --dir           Override prompt directories (comma separated)
   --query         Provide a query for prompt selection
   --interactive   Force interactive selection
   --copy          Copy the chosen prompt to the clipboard
-  --limit         Maximum number of results for search`)
+  --copy-ttl      Copy, then restore the previous clipboard after N seconds
+  --to            Hand the chosen prompt to a target configured in settings
+  --limit         Maximum number of results for search
+  --tag           Filter ls or export by tag, including nested subtags (tag/subtag)
+  --json          Print cat output as JSON, including chat messages and roles
+  --from          Format of the export read by import
+  --format        Bundle format written by export (guessed from --output)
+  --output, -o    File written by export instead of stdout`)
 }
 
 func runCompletion(args []string, out io.Writer) error {
@@ -424,19 +892,23 @@ _pm_complete() {
   local cur prev
   _init_completion || return
 
-  local commands="pick search ls cat mesh help"
+  local commands="pick search ls cat mesh run serve clipboard doctor import export sync config help"
   if [[ ${COMP_CWORD} -eq 1 ]]; then
     COMPREPLY=( $(compgen -W "$commands" -- "$cur") )
     return
   fi
 
   case ${COMP_WORDS[1]} in
-    cat|mesh)
+    cat|mesh|run)
       local prompts
-      prompts=$(pm ls 2>/dev/null)
+      prompts=$(pm ls 2>/dev/null | cut -f1)
       COMPREPLY=( $(compgen -W "$prompts" -- "$cur") )
       return
       ;;
+    config)
+      COMPREPLY=( $(compgen -W "show get set path init" -- "$cur") )
+      return
+      ;;
   esac
 }
 complete -F _pm_complete pm
@@ -453,6 +925,14 @@ _pm() {
     'ls:list prompts'
     'cat:print a prompt'
     'mesh:combine prompts'
+    'run:send prompts to a chat model'
+    'serve:serve the prompt library'
+    'clipboard:inspect clipboard providers'
+    'doctor:report config, prompt dirs and load problems'
+    'import:import prompts from other tools'
+    'export:export prompts as a bundle'
+    'sync:update git sources and prompt packs'
+    'config:show or edit settings'
     'help:show help'
   )
 
@@ -466,11 +946,16 @@ _pm() {
       ;;
     args)
       case $words[2] in
-        cat|mesh)
+        cat|mesh|run)
           local -a prompts
-          prompts=("${(@f)$(pm ls 2>/dev/null)}")
+          prompts=("${(@f)$(pm ls 2>/dev/null | cut -f1)}")
           _describe 'prompt' prompts
           ;;
+        config)
+          local -a subcommands
+          subcommands=(show get set path init)
+          _describe 'subcommand' subcommands
+          ;;
       esac
       ;;
   esac
@@ -480,18 +965,57 @@ compdef _pm pm
 `
 
 const fishCompletion = `# fish completion for pm
-complete -c pm -f -n '__fish_use_subcommand' -a 'pick search ls cat mesh help'
-complete -c pm -f -n '__fish_seen_subcommand_from cat mesh' -a '(pm ls 2>/dev/null)'
+complete -c pm -f -n '__fish_use_subcommand' -a 'pick search ls cat mesh run serve clipboard doctor import export sync config help'
+complete -c pm -f -n '__fish_seen_subcommand_from cat mesh run' -a '(pm ls 2>/dev/null | string split -f1 \t)'
+complete -c pm -f -n '__fish_seen_subcommand_from config' -a 'show get set path init'
 `
 
-func outputPrompt(content string, copyToClipboard bool, out io.Writer) error {
+// outputOptions describe what happens to a chosen prompt besides printing it.
+type outputOptions struct {
+	copy bool
+	// copyTTL implies copy and restores the previous clipboard afterwards.
+	copyTTL time.Duration
+	// configPath is the settings file the restore process reads.
+	configPath string
+	// target receives the prompt instead of stdout when send is set; the
+	// picker can also set send through its keybinding.
+	target *targetSpec
+	send   bool
+}
+
+func outputPrompt(content string, output outputOptions, out io.Writer) error {
 	cleaned := normalizeContent(content)
-	if err := writePrompt(out, cleaned); err != nil {
+	sending := output.send && output.target != nil
+	if !sending {
+		if err := writePrompt(out, cleaned); err != nil {
+			return err
+		}
+	}
+	if err := copyPrompt(cleaned, output); err != nil {
 		return err
 	}
-	if copyToClipboard {
-		if err := clipboard.Copy(cleaned); err != nil {
-			return fmt.Errorf("copy to clipboard: %w", err)
+	if sending {
+		return execTarget(*output.target, cleaned)
+	}
+	return nil
+}
+
+func copyPrompt(cleaned string, output outputOptions) error {
+	if !output.copy && output.copyTTL <= 0 {
+		return nil
+	}
+
+	var previous string
+	if output.copyTTL > 0 {
+		// An unreadable clipboard is treated as empty so the copy is still cleared.
+		previous, _ = clipboard.Paste()
+	}
+	if err := clipboard.Copy(cleaned); err != nil {
+		return fmt.Errorf("copy to clipboard: %w", err)
+	}
+	if output.copyTTL > 0 {
+		if err := scheduleClipboardRestore(output.configPath, output.copyTTL, previous, cleaned); err != nil {
+			return fmt.Errorf("schedule clipboard restore: %w", err)
 		}
 	}
 	return nil
@@ -506,14 +1030,6 @@ func writePrompt(out io.Writer, content string) error {
 	return err
 }
 
-func expandDirs(dirs []string) []string {
-	expanded := make([]string, 0, len(dirs))
-	for _, dir := range dirs {
-		expanded = append(expanded, expandTilde(dir))
-	}
-	return expanded
-}
-
 func expandTilde(path string) string {
 	if path == "" || path[0] != '~' {
 		return path
@@ -538,6 +1054,7 @@ func normalizeQuery(value string) string {
 		"_", " ",
 		"-", " ",
 		"/", " ",
+		"#", " ",
 		".", " ",
 		",", " ",
 		"\n", " ",
@@ -549,7 +1066,7 @@ func normalizeQuery(value string) string {
 }
 
 func aliasMatch(p prompt.Prompt, query string) bool {
-	for _, alias := range promptAliases(p) {
+	for _, alias := range p.Aliases {
 		if strings.EqualFold(alias, query) {
 			return true
 		}
@@ -561,34 +1078,10 @@ func aliasMatchNormalized(p prompt.Prompt, normalizedQuery string) bool {
 	if normalizedQuery == "" {
 		return false
 	}
-	for _, alias := range promptAliases(p) {
+	for _, alias := range p.Aliases {
 		if normalizeQuery(alias) == normalizedQuery {
 			return true
 		}
 	}
 	return false
 }
-
-func promptAliases(p prompt.Prompt) []string {
-	if p.FrontMatter == nil {
-		return nil
-	}
-	raw, ok := p.FrontMatter["aliases"]
-	if !ok || raw == nil {
-		return nil
-	}
-	switch v := raw.(type) {
-	case string:
-		return []string{v}
-	case []string:
-		return append([]string(nil), v...)
-	case []any:
-		var out []string
-		for _, item := range v {
-			out = append(out, fmt.Sprint(item))
-		}
-		return out
-	default:
-		return []string{fmt.Sprint(v)}
-	}
-}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hzionn/prompt-manager-cli/internal/clipboard"
 	"github.com/hzionn/prompt-manager-cli/internal/config"
@@ -61,7 +67,7 @@ func TestRunPickWithQueryCopiesSelection(t *testing.T) {
 	defer clipboard.SetProvider(nil)
 
 	var out bytes.Buffer
-	if err := runPickWithQuery(ctx, "code-review", "", true, &out); err != nil {
+	if err := runPickWithQuery(ctx, "code-review", "", outputOptions{copy: true}, &out); err != nil {
 		t.Fatalf("runPickWithQuery error = %v", err)
 	}
 
@@ -82,7 +88,7 @@ func TestRunPickInteractiveCopiesSelection(t *testing.T) {
 	input := strings.NewReader("1\n")
 	var out bytes.Buffer
 
-	if err := runPickInteractive(ctx, "", true, input, &out); err != nil {
+	if err := runPickInteractive(ctx, "", outputOptions{copy: true}, input, &out); err != nil {
 		t.Fatalf("runPickInteractive error = %v", err)
 	}
 
@@ -91,12 +97,30 @@ func TestRunPickInteractiveCopiesSelection(t *testing.T) {
 	}
 }
 
+func TestWatchPromptsClosesUpdatesWhenStopped(t *testing.T) {
+	watchCtx, stop := context.WithCancel(context.Background())
+	_, updates, err := watchPrompts(watchCtx, testAppContext(), "")
+	if err != nil {
+		t.Fatalf("watchPrompts error = %v", err)
+	}
+	stop()
+
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatal("expected no snapshots after stopping")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the updates channel to close")
+	}
+}
+
 func testAppContext() appContext {
 	dir := filepath.Join("..", "..", "testdata", "prompts")
 	settings := config.Settings{
 		DefaultDirs: []string{dir},
 		FileSystem: config.FileSystemSettings{
-			Extensions:     []string{".md", ".txt"},
+			Extensions:     []string{".md", ".txt", ".prompt.yaml", ".prompt.yml", ".prompt.json"},
 			IgnorePatterns: nil,
 			MaxFileSizeKB:  1024,
 		},
@@ -113,3 +137,344 @@ func testAppContext() appContext {
 		searchOpts: search.Options{MaxResults: settings.FuzzySearch.MaxResults},
 	}
 }
+
+func TestConfigureClipboardReportsUnknownProviderOnCopy(t *testing.T) {
+	configureClipboard(config.ClipboardSettings{Provider: "carrier-pigeon"})
+	defer clipboard.SetProvider(nil)
+
+	err := clipboard.Copy("hello")
+	if err == nil || !strings.Contains(err.Error(), "carrier-pigeon") {
+		t.Fatalf("expected unknown provider error, got %v", err)
+	}
+}
+
+func TestRunClipboardDoctorListsProviders(t *testing.T) {
+	ctx := testAppContext()
+	ctx.settings.Clipboard = config.ClipboardSettings{Provider: "termux"}
+	var out bytes.Buffer
+
+	if err := runClipboard(ctx, []string{"doctor"}, nil, &out); err != nil {
+		t.Fatalf("runClipboard error = %v", err)
+	}
+
+	report := out.String()
+	if !strings.Contains(report, "clipboard: provider termux") {
+		t.Fatalf("expected configured provider in report, got %q", report)
+	}
+	for _, name := range clipboard.Names()[1:] {
+		if !strings.Contains(report, name) {
+			t.Fatalf("expected provider %q in report, got %q", name, report)
+		}
+	}
+}
+
+type memoryClipboard struct {
+	text string
+}
+
+func (m *memoryClipboard) Write(text string) error {
+	m.text = text
+	return nil
+}
+
+func (m *memoryClipboard) Read() (string, error) { return m.text, nil }
+
+func TestRunPickWithCopyTTLSchedulesRestore(t *testing.T) {
+	ctx := testAppContext()
+	ctx.configPath = "/etc/pm/settings.toml"
+	mem := &memoryClipboard{text: "previous contents"}
+	clipboard.SetProvider(mem)
+	defer clipboard.SetProvider(nil)
+
+	var gotTTL time.Duration
+	var gotPrevious, gotCopied string
+	var gotConfig string
+	scheduleClipboardRestore = func(configPath string, ttl time.Duration, previous, copied string) error {
+		gotConfig, gotTTL, gotPrevious, gotCopied = configPath, ttl, previous, copied
+		return nil
+	}
+	defer func() { scheduleClipboardRestore = spawnClipboardRestore }()
+
+	var out bytes.Buffer
+	if err := runPick(ctx, []string{"--copy-ttl", "5", "--query", "code-review"}, nil, &out); err != nil {
+		t.Fatalf("runPick error = %v", err)
+	}
+
+	if !strings.Contains(mem.text, "Code Review") {
+		t.Fatalf("expected prompt copied to clipboard, got %q", mem.text)
+	}
+	if gotConfig != ctx.configPath || gotTTL != 5*time.Second || gotPrevious != "previous contents" || gotCopied != mem.text {
+		t.Fatalf("unexpected restore request: config=%q ttl=%v previous=%q copied=%q", gotConfig, gotTTL, gotPrevious, gotCopied)
+	}
+}
+
+func TestClipboardRestoreArgsPassConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.toml")
+	if err := os.WriteFile(path, []byte("[clipboard]\ncommand = \"lemonade\"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got := strings.Join(clipboardRestoreArgs(path, 5*time.Second), " ")
+	if want := "--config " + path + " clipboard restore --after 5s"; got != want {
+		t.Fatalf("expected args %q, got %q", want, got)
+	}
+
+	got = strings.Join(clipboardRestoreArgs(filepath.Join(t.TempDir(), "missing.toml"), time.Second), " ")
+	if got != "clipboard restore --after 1s" {
+		t.Fatalf("expected a missing settings file to be left out, got %q", got)
+	}
+}
+
+func TestRunClipboardRestoreAppliesPayload(t *testing.T) {
+	mem := &memoryClipboard{text: "secret"}
+	clipboard.SetProvider(mem)
+	defer clipboard.SetProvider(nil)
+
+	payload := strings.NewReader(`{"previous":"before","copied":"secret"}`)
+	if err := runClipboard(testAppContext(), []string{"restore", "--after", "0s"}, payload, io.Discard); err != nil {
+		t.Fatalf("runClipboard restore error = %v", err)
+	}
+	if mem.text != "before" {
+		t.Fatalf("expected clipboard restored to %q, got %q", "before", mem.text)
+	}
+}
+
+func TestRunClipboardListsSubcommands(t *testing.T) {
+	err := runClipboard(testAppContext(), []string{"paste"}, nil, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "expected doctor or restore") {
+		t.Fatalf("expected both subcommands to be listed, got %v", err)
+	}
+}
+
+func TestRunMeshFromClipboard(t *testing.T) {
+	ctx := testAppContext()
+	clipboard.SetProvider(&memoryClipboard{text: "Clipboard payload"})
+	defer clipboard.SetProvider(nil)
+
+	input := strings.NewReader("Piped input\n")
+	var out bytes.Buffer
+	if err := runMesh(ctx, []string{"--from-clipboard", "code-review"}, input, &out); err != nil {
+		t.Fatalf("runMesh error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Clipboard payload") {
+		t.Fatalf("expected clipboard payload in mesh output, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "Piped input") {
+		t.Fatalf("expected stdin to be ignored with --from-clipboard, got %q", out.String())
+	}
+}
+
+func TestRunListFiltersByTag(t *testing.T) {
+	ctx := testAppContext()
+	var out bytes.Buffer
+
+	if err := runList(ctx, []string{"--tag", "#Launch"}, &out); err != nil {
+		t.Fatalf("runList error = %v", err)
+	}
+
+	if got := strings.TrimSpace(out.String()); got != "product-brief" {
+		t.Fatalf("expected only product-brief, got %q", got)
+	}
+}
+
+func TestRunListAndSearchShowSourceLabels(t *testing.T) {
+	ctx := testAppContext()
+	team := t.TempDir()
+	if err := os.WriteFile(filepath.Join(team, "code-review.md"), []byte("Team review checklist"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(team, "notes.txt"), []byte("Not a team prompt"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	ctx.settings.Sources = []config.SourceSettings{
+		{Path: team, Label: "team", Priority: 10, Enabled: true, ReadOnly: true, Extensions: []string{".md"}},
+		{Path: filepath.Join(team, "missing"), Label: "old", Enabled: false},
+	}
+
+	var out bytes.Buffer
+	if err := runList(ctx, nil, &out); err != nil {
+		t.Fatalf("runList error = %v", err)
+	}
+	want := "brainstorm\ncode-review\tteam\ncode-review\nproduct-brief\n"
+	if got := out.String(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	out.Reset()
+	if err := runSearch(ctx, []string{"code-review"}, nil, &out); err != nil {
+		t.Fatalf("runSearch error = %v", err)
+	}
+	if !strings.Contains(out.String(), "code-review\t"+filepath.Join(team, "code-review.md")+"\tteam\n") {
+		t.Fatalf("expected the team prompt with its label, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := runCat(ctx, []string{"code-review"}, &out); err != nil {
+		t.Fatalf("runCat error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Team review checklist") {
+		t.Fatalf("expected the higher priority source to win, got %q", out.String())
+	}
+}
+
+func TestNewAppContextLoadsProjectPrompts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	global := filepath.Join(home, "prompts")
+	root := t.TempDir()
+	nested := filepath.Join(root, "cmd", "tool")
+	for _, dir := range []string{global, filepath.Join(root, config.ProjectDir), nested} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(global, "release.md"):                  "Global release notes",
+		filepath.Join(global, "standup.md"):                  "Global standup",
+		filepath.Join(root, config.ProjectDir, "release.md"): "Project release checklist",
+		filepath.Join(root, config.ProjectFile):              "[fuzzy_search]\nmax_results = 7\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	t.Chdir(nested)
+
+	ctx, err := newAppContext("")
+	if err != nil {
+		t.Fatalf("newAppContext error = %v", err)
+	}
+	if ctx.searchOpts.MaxResults != 7 {
+		t.Fatalf("expected the project max_results, got %d", ctx.searchOpts.MaxResults)
+	}
+
+	var out bytes.Buffer
+	if err := runList(ctx, nil, &out); err != nil {
+		t.Fatalf("runList error = %v", err)
+	}
+	if want := "release\tproject\nrelease\nstandup\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	if err := runCat(ctx, []string{"release"}, &out); err != nil {
+		t.Fatalf("runCat error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Project release checklist") {
+		t.Fatalf("expected the project prompt to win, got %q", out.String())
+	}
+}
+
+func TestRunLayersConfigFileAndEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Chdir(t.TempDir())
+	dir := t.TempDir()
+	envDir := t.TempDir()
+	for path, content := range map[string]string{
+		filepath.Join(dir, "from-file.md"):     "File prompt",
+		filepath.Join(envDir, "from-env.md"):   "Env prompt",
+		filepath.Join(envDir, "settings.toml"): "default_dir = " + strconv.Quote(envDir) + "\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	configPath := filepath.Join(dir, "settings.toml")
+	if err := os.WriteFile(configPath, []byte("default_dir = "+strconv.Quote(dir)+"\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	t.Setenv(config.ConfigEnv, filepath.Join(envDir, "settings.toml"))
+	var out bytes.Buffer
+	if err := run([]string{"ls"}, nil, &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+	if out.String() != "from-env\n" {
+		t.Fatalf("expected the PM_CONFIG file to be used, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"ls", "--config=" + configPath}, nil, &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+	if out.String() != "from-file\n" {
+		t.Fatalf("expected --config to win over PM_CONFIG, got %q", out.String())
+	}
+
+	t.Setenv("PM_DEFAULT_DIR", envDir)
+	out.Reset()
+	if err := run([]string{"--config", configPath, "ls"}, nil, &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+	if out.String() != "from-env\n" {
+		t.Fatalf("expected PM_DEFAULT_DIR to override the file, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"--config", configPath, "ls", "--dir", dir}, nil, &out); err != nil {
+		t.Fatalf("run error = %v", err)
+	}
+	if out.String() != "from-file\n" {
+		t.Fatalf("expected --dir to override the environment, got %q", out.String())
+	}
+}
+
+func TestRunReportsMalformedConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "settings.toml")
+	if err := os.WriteFile(configPath, []byte("[ui]\ntruncate_length = \"wide\"\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	err := run([]string{"--config", configPath, "ls"}, nil, io.Discard)
+	if err == nil || !strings.HasPrefix(err.Error(), configPath+":2:19: ") {
+		t.Fatalf("expected a positioned config error, got %v", err)
+	}
+
+	err = run([]string{"--config", filepath.Join(t.TempDir(), "missing.toml"), "ls"}, nil, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Fatalf("expected a missing --config file to be an error, got %v", err)
+	}
+}
+
+func TestRunCatResolvesSplitSection(t *testing.T) {
+	dir := t.TempDir()
+	content := "# Team\n\n## Code Review\nReview this diff.\n\n## Release Notes\nWrite release notes.\n"
+	if err := os.WriteFile(filepath.Join(dir, "team.md"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+	ctx := testAppContext()
+	ctx.promptOpts.SplitSections = true
+
+	var out bytes.Buffer
+	if err := runCat(ctx, []string{"--dir", dir, "team#code-review"}, &out); err != nil {
+		t.Fatalf("runCat error = %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "Review this diff." {
+		t.Fatalf("expected the Code Review section, got %q", got)
+	}
+}
+
+func TestRunCatJSONKeepsMessageRoles(t *testing.T) {
+	dir := t.TempDir()
+	structured := `{"messages": [{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Summarize."}]}`
+	if err := os.WriteFile(filepath.Join(dir, "brief.prompt.json"), []byte(structured), 0o644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runCat(testAppContext(), []string{"--json", "--dir", dir, "brief"}, &out); err != nil {
+		t.Fatalf("runCat error = %v", err)
+	}
+
+	var got prompt.Prompt
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("decode output %q: %v", out.String(), err)
+	}
+	if got.Name != "brief" || len(got.Messages) != 2 || got.Messages[0].Role != "system" {
+		t.Fatalf("expected messages with roles, got %+v", got)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/hzionn/prompt-manager-cli/internal/chat"
+	"github.com/hzionn/prompt-manager-cli/internal/config"
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+// runChat implements `pm run`: it meshes the named prompts with any piped
+// input and streams the model's reply from the configured chat endpoint.
+func runChat(ctx appContext, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var dirFlag string
+	var model string
+	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	fs.StringVar(&model, "model", "", "Override the configured chat model")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		return errors.New("run requires at least one prompt name")
+	}
+
+	prompts, err := loadPrompts(ctx, dirFlag)
+	if err != nil {
+		return err
+	}
+
+	var messages []chat.Message
+	hint := ""
+	for _, name := range names {
+		promptItem, err := resolvePromptByQuery(prompts, name)
+		if err != nil {
+			return err
+		}
+		for _, message := range promptItem.ChatMessages() {
+			messages = appendMessage(messages, message.Role, message.Content)
+		}
+		if hint == "" {
+			hint = promptItem.Model
+		}
+	}
+
+	if shouldReadFromInput(in) {
+		if extra, err := io.ReadAll(in); err == nil && len(extra) > 0 {
+			messages = appendMessage(messages, prompt.RoleUser, string(extra))
+		}
+	}
+
+	client := chatClient(ctx.settings.Chat)
+	switch {
+	case model != "":
+		client.Model = model
+	case client.Model == "":
+		// A structured prompt's model is only a hint: the configured model
+		// wins because it is known to exist on the endpoint.
+		client.Model = hint
+	}
+
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := client.Stream(runCtx, messages, out); err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+// appendMessage adds a message to the conversation, meshing consecutive
+// messages of the same role into one.
+func appendMessage(messages []chat.Message, role, content string) []chat.Message {
+	content = normalizeContent(content)
+	if n := len(messages); n > 0 && messages[n-1].Role == role {
+		messages[n-1].Content = strings.Join([]string{messages[n-1].Content, content}, "\n\n")
+		return messages
+	}
+	return append(messages, chat.Message{Role: role, Content: content})
+}
+
+func chatClient(settings config.ChatSettings) chat.Client {
+	client := chat.Client{
+		BaseURL: settings.BaseURL,
+		Model:   settings.Model,
+	}
+	if settings.APIKeyEnv != "" {
+		client.APIKey = os.Getenv(settings.APIKeyEnv)
+	}
+	return client
+}
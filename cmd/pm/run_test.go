@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/chat"
+)
+
+func TestRunChatStreamsReplyForMeshedPrompt(t *testing.T) {
+	var messages []chat.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []chat.Message `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		messages = payload.Messages
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Looks good.\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	ctx := testAppContext()
+	ctx.settings.Chat.BaseURL = server.URL
+	input := strings.NewReader("func main() {}\n")
+	var out bytes.Buffer
+
+	if err := runChat(ctx, []string{"code-review"}, input, &out); err != nil {
+		t.Fatalf("runChat error = %v", err)
+	}
+
+	if out.String() != "Looks good.\n" {
+		t.Fatalf("expected streamed reply, got %q", out.String())
+	}
+	if len(messages) != 1 || messages[0].Role != "user" {
+		t.Fatalf("expected a single user message, got %+v", messages)
+	}
+	if !strings.Contains(messages[0].Content, "Code Review") || !strings.HasSuffix(messages[0].Content, "func main() {}") {
+		t.Fatalf("expected prompt meshed with stdin, got %q", messages[0].Content)
+	}
+}
+
+func TestRunChatSendsStructuredPromptMessages(t *testing.T) {
+	var payload struct {
+		Model    string         `json:"model"`
+		Messages []chat.Message `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Bonjour"}}]}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	structured := "model: small-model\nmessages:\n  - role: system\n    content: Translate into French.\n  - role: user\n    content: Translate the text below.\n"
+	if err := os.WriteFile(filepath.Join(dir, "translate.prompt.yaml"), []byte(structured), 0o644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+
+	ctx := testAppContext()
+	ctx.settings.Chat.BaseURL = server.URL
+	ctx.settings.Chat.Model = ""
+	var out bytes.Buffer
+	if err := runChat(ctx, []string{"--dir", dir, "translate"}, strings.NewReader("Hello\n"), &out); err != nil {
+		t.Fatalf("runChat error = %v", err)
+	}
+
+	if payload.Model != "small-model" {
+		t.Fatalf("expected the prompt's model hint, got %q", payload.Model)
+	}
+	want := []chat.Message{
+		{Role: "system", Content: "Translate into French."},
+		{Role: "user", Content: "Translate the text below.\n\nHello"},
+	}
+	if fmt.Sprint(payload.Messages) != fmt.Sprint(want) {
+		t.Fatalf("expected %+v, got %+v", want, payload.Messages)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/hzionn/prompt-manager-cli/internal/httpapi"
+	"github.com/hzionn/prompt-manager-cli/internal/mcp"
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+// version is reported by the server modes.
+var version = "dev"
+
+func runServe(ctx appContext, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var dirFlag string
+	var mcpMode bool
+	var httpMode bool
+	var addr string
+	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	fs.BoolVar(&mcpMode, "mcp", false, "Serve the Model Context Protocol over stdio")
+	fs.BoolVar(&httpMode, "http", false, "Serve a JSON HTTP API")
+	fs.StringVar(&addr, "addr", "127.0.0.1:7700", "Listen address for --http")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if mcpMode == httpMode {
+		return errors.New("serve requires exactly one of --mcp or --http")
+	}
+
+	serveCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if httpMode {
+		return serveHTTP(serveCtx, ctx, dirFlag, addr)
+	}
+
+	lib, _, err := watchPrompts(serveCtx, ctx, dirFlag)
+	if err != nil {
+		return err
+	}
+
+	server := mcp.Server{
+		Prompts:    lib.Prompts,
+		SearchOpts: ctx.searchOpts,
+		Name:       "pm",
+		Version:    version,
+	}
+	return server.Serve(serveCtx, in, out)
+}
+
+func serveHTTP(serveCtx context.Context, ctx appContext, dirFlag, addr string) error {
+	lib, updates, err := watchPrompts(serveCtx, ctx, dirFlag)
+	if err != nil {
+		return err
+	}
+	go warnChangedDiagnostics(lib, updates)
+	handler := httpapi.New(httpapi.Options{Library: lib, SearchOpts: ctx.searchOpts})
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "pm: serving %s on http://%s\n", formatPromptDirs(ctx, dirFlag), listener.Addr())
+
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Serve(listener) }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-serveCtx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// warnChangedDiagnostics repeats the warning about unloadable files whenever
+// a change to the watched library alters the set of them.
+func warnChangedDiagnostics(lib *prompt.Library, updates <-chan []prompt.Prompt) {
+	previous := lib.Diagnostics()
+	for range updates {
+		current := lib.Diagnostics()
+		same := slices.EqualFunc(current, previous, func(a, b prompt.Diagnostic) bool {
+			return a.Error() == b.Error()
+		})
+		if !same {
+			warnDiagnostics(current)
+			previous = current
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunServeMCPListsPrompts(t *testing.T) {
+	ctx := testAppContext()
+	input := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}` + "\n")
+	var out bytes.Buffer
+
+	if err := runServe(ctx, []string{"--mcp"}, input, &out); err != nil {
+		t.Fatalf("runServe error = %v", err)
+	}
+
+	for _, name := range []string{"brainstorm", "code-review", "product-brief"} {
+		if !strings.Contains(out.String(), `"name":"`+name+`"`) {
+			t.Fatalf("expected %s in prompts/list response, got %q", name, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "Outline the critical launch details") {
+		t.Fatalf("expected summary as description, got %q", out.String())
+	}
+}
+
+func TestRunServeRequiresMode(t *testing.T) {
+	if err := runServe(testAppContext(), nil, nil, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected error without a server mode")
+	}
+	if err := runServe(testAppContext(), []string{"--mcp", "--http"}, nil, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected error when both server modes are requested")
+	}
+}
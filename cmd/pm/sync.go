@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/hzionn/prompt-manager-cli/internal/remote"
+)
+
+// runSync implements `pm sync`: it clones or updates every git prompt source
+// and downloads changed prompt packs into cache_dir.
+func runSync(ctx appContext, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var dirFlag string
+	fs.StringVar(&dirFlag, "dir", "", "Prompt directories (comma separated)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var repos []remote.Git
+	for _, entry := range sourceEntries(ctx, dirFlag) {
+		if repo, ok := remote.ParseGit(entry.Path); ok {
+			repos = append(repos, repo)
+		}
+	}
+	var packList []remote.Pack
+	if dirFlag == "" {
+		packList = packs(ctx)
+	}
+	if len(repos) == 0 && len(packList) == 0 {
+		_, err := fmt.Fprintln(out, "No remote prompt sources configured.")
+		return err
+	}
+
+	syncCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	failed := 0
+	for _, repo := range repos {
+		if err := repo.Sync(syncCtx, cacheDir(ctx)); err != nil {
+			failed++
+			fmt.Fprintf(out, "failed %s: %v\n", repo, err)
+			continue
+		}
+		fmt.Fprintf(out, "synced %s -> %s\n", repo, repo.Dir(cacheDir(ctx)))
+	}
+	for _, pack := range packList {
+		updated, err := pack.Sync(syncCtx, cacheDir(ctx))
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(out, "failed pack %s: %v\n", pack.Name, err)
+			continue
+		case !updated:
+			fmt.Fprintf(out, "pack %s is up to date\n", pack.Name)
+			continue
+		}
+		note := ""
+		if !pack.Verified() {
+			note = " (unverified: set sha256 or public_key)"
+		}
+		fmt.Fprintf(out, "updated pack %s -> %s%s\n", pack.Name, pack.Dir(cacheDir(ctx)), note)
+	}
+	if total := len(repos) + len(packList); failed > 0 {
+		return fmt.Errorf("%d of %d sources failed to sync", failed, total)
+	}
+	return nil
+}
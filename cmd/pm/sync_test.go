@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/config"
+)
+
+func TestRunSyncLoadsGitSourceReadOnly(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	work, bare := filepath.Join(root, "work"), filepath.Join(root, "team.git")
+	if err := os.MkdirAll(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(work, "standup.md"), []byte("Daily standup\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", work},
+		{"-C", work, "add", "standup.md"},
+		{"-C", work, "-c", "user.name=pm", "-c", "user.email=pm@example.com", "commit", "--quiet", "-m", "init"},
+		{"clone", "--quiet", "--bare", work, bare},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	ctx := testAppContext()
+	ctx.settings.CacheDir = filepath.Join(root, "cache")
+	ctx.settings.DefaultDirs = []string{"file://" + filepath.ToSlash(bare)}
+
+	var out bytes.Buffer
+	if err := runList(ctx, nil, &out); err != nil || out.Len() != 0 {
+		t.Fatalf("expected nothing before sync, got %q (%v)", out.String(), err)
+	}
+
+	if err := runSync(ctx, nil, &out); err != nil {
+		t.Fatalf("runSync error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "synced file://") {
+		t.Fatalf("unexpected sync output %q", out.String())
+	}
+
+	out.Reset()
+	if err := runList(ctx, nil, &out); err != nil {
+		t.Fatalf("runList error = %v", err)
+	}
+	if got := out.String(); got != "standup\t"+ctx.settings.DefaultDirs[0]+"\n" {
+		t.Fatalf("expected the prompt with its origin, got %q", got)
+	}
+
+	err := runImport(ctx, []string{"--from", "raycast", filepath.Join(root, "commands.json")}, &out)
+	if err == nil || !strings.Contains(err.Error(), "no writable prompt directory") {
+		t.Fatalf("expected import to refuse read-only sources, got %v", err)
+	}
+}
+
+func TestRunSyncDownloadsPacksForOfflineUse(t *testing.T) {
+	index := []byte(`[{"name": "retro", "content": "Run a retrospective.\n"}]`)
+	sum := sha256.Sum256(index)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(index)
+	}))
+
+	ctx := testAppContext()
+	ctx.settings.CacheDir = t.TempDir()
+	ctx.settings.Packs = []config.PackSettings{{Name: "agile", URL: server.URL + "/index.json", SHA256: hex.EncodeToString(sum[:])}}
+
+	var out bytes.Buffer
+	if err := runSync(ctx, nil, &out); err != nil {
+		t.Fatalf("runSync error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "updated pack agile -> ") || strings.Contains(out.String(), "unverified") {
+		t.Fatalf("unexpected sync output %q", out.String())
+	}
+	server.Close()
+
+	out.Reset()
+	if err := runList(ctx, nil, &out); err != nil {
+		t.Fatalf("runList error = %v", err)
+	}
+	if !strings.Contains(out.String(), "retro\tagile\n") {
+		t.Fatalf("expected the pack prompt with its label offline, got %q", out.String())
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/hzionn/prompt-manager-cli/internal/config"
+)
+
+// targetSpec is a resolved external program that receives a picked prompt.
+type targetSpec struct {
+	name string
+	config.TargetSettings
+}
+
+// resolveTarget looks up a configured target by name.
+func resolveTarget(settings config.Settings, name string) (*targetSpec, error) {
+	target, ok := settings.Targets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q (configured: %s)", name, formatTargetNames(settings.Targets))
+	}
+	if strings.TrimSpace(target.Command) == "" {
+		return nil, fmt.Errorf("target %q has no command", name)
+	}
+	switch target.Input {
+	case "", "stdin", "arg":
+	default:
+		return nil, fmt.Errorf("target %q: input must be \"stdin\" or \"arg\", got %q", name, target.Input)
+	}
+	return &targetSpec{name: name, TargetSettings: target}, nil
+}
+
+// keybindingTarget returns the target the Ctrl+T keybinding hands the prompt
+// to: the --to target, or else default_target, which is only resolved here so
+// that a misconfigured default does not get in the way of other picks.
+func keybindingTarget(settings config.Settings, target *targetSpec) (*targetSpec, error) {
+	if target != nil {
+		return target, nil
+	}
+	target, err := resolveTarget(settings, settings.DefaultTarget)
+	if err != nil {
+		return nil, fmt.Errorf("default_target: %w", err)
+	}
+	return target, nil
+}
+
+func formatTargetNames(targets map[string]config.TargetSettings) string {
+	if len(targets) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// execTarget replaces the pm process with the target. It is a variable so
+// tests can intercept the hand-off.
+var execTarget = launchTarget
+
+func launchTarget(target targetSpec, content string) error {
+	path, err := exec.LookPath(expandTilde(target.Command))
+	if err != nil {
+		return fmt.Errorf("target %q: %w", target.name, err)
+	}
+
+	argv := append([]string{target.Command}, target.Args...)
+	if target.Input == "arg" {
+		return execProcess(path, append(argv, content), nil)
+	}
+
+	stdin, err := promptInputFile(content)
+	if err != nil {
+		return fmt.Errorf("target %q: %w", target.name, err)
+	}
+	return execProcess(path, argv, stdin)
+}
+
+// promptInputFile stores content in an unlinked temporary file positioned at
+// the start, suitable for use as the target's stdin.
+func promptInputFile(content string) (*os.File, error) {
+	file, err := os.CreateTemp("", "pm-prompt-*")
+	if err != nil {
+		return nil, err
+	}
+	name := file.Name()
+	if _, err := file.WriteString(content + "\n"); err != nil {
+		file.Close()
+		os.Remove(name)
+		return nil, err
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		file.Close()
+		os.Remove(name)
+		return nil, err
+	}
+	// The open descriptor keeps the data readable; on platforms that refuse to
+	// remove open files the temp file is simply left behind.
+	os.Remove(name)
+	return file, nil
+}
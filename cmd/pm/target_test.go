@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/config"
+)
+
+func TestRunPickWithTargetExecsTarget(t *testing.T) {
+	ctx := testAppContext()
+	ctx.settings.Targets = map[string]config.TargetSettings{
+		"codex": {Command: "codex", Input: "arg"},
+	}
+
+	var gotTarget targetSpec
+	var gotContent string
+	execTarget = func(target targetSpec, content string) error {
+		gotTarget, gotContent = target, content
+		return nil
+	}
+	defer func() { execTarget = launchTarget }()
+
+	var out bytes.Buffer
+	if err := runPick(ctx, []string{"--to", "codex", "--query", "code-review"}, nil, &out); err != nil {
+		t.Fatalf("runPick error = %v", err)
+	}
+
+	if gotTarget.name != "codex" || gotTarget.Input != "arg" {
+		t.Fatalf("unexpected target: %+v", gotTarget)
+	}
+	if !strings.Contains(gotContent, "Code Review") {
+		t.Fatalf("expected prompt content handed to target, got %q", gotContent)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing on stdout when sending to a target, got %q", out.String())
+	}
+}
+
+func TestRunPickRejectsUnknownTarget(t *testing.T) {
+	ctx := testAppContext()
+	ctx.settings.Targets = map[string]config.TargetSettings{"llm": {Command: "llm"}}
+
+	err := runPick(ctx, []string{"--to", "codex", "--query", "code-review"}, nil, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "llm") {
+		t.Fatalf("expected unknown target error listing configured targets, got %v", err)
+	}
+}
+
+func TestRunPickIgnoresMisconfiguredDefaultTarget(t *testing.T) {
+	ctx := testAppContext()
+	ctx.settings.DefaultTarget = "codex"
+
+	var out bytes.Buffer
+	if err := runPick(ctx, []string{"--query", "code-review"}, nil, &out); err != nil {
+		t.Fatalf("runPick error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Code Review") {
+		t.Fatalf("expected the prompt on stdout, got %q", out.String())
+	}
+
+	if _, err := keybindingTarget(ctx.settings, nil); err == nil || !strings.Contains(err.Error(), "default_target") {
+		t.Fatalf("expected the keybinding to report default_target, got %v", err)
+	}
+}
+
+func TestPromptInputFileIsReadable(t *testing.T) {
+	file, err := promptInputFile("hello target")
+	if err != nil {
+		t.Fatalf("promptInputFile error = %v", err)
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(file); err != nil {
+		t.Fatalf("read prompt file: %v", err)
+	}
+	if buf.String() != "hello target\n" {
+		t.Fatalf("expected prompt content, got %q", buf.String())
+	}
+}
@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Message is a single chat message sent to the model.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Client talks to an OpenAI-compatible chat completions endpoint.
+type Client struct {
+	// BaseURL is the server root, with or without a trailing /v1.
+	BaseURL    string
+	Model      string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+type completionRequest struct {
+	Model    string    `json:"model,omitempty"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+}
+
+type completionChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Endpoint returns the chat completions URL derived from BaseURL.
+func (c Client) Endpoint() string {
+	base := strings.TrimRight(c.BaseURL, "/")
+	if strings.HasSuffix(base, "/v1") {
+		return base + "/chat/completions"
+	}
+	return base + "/v1/chat/completions"
+}
+
+// Stream posts messages to the endpoint and writes the response content to out
+// as it arrives. Servers that ignore the stream flag and reply with a single
+// JSON body are handled as well.
+func (c Client) Stream(ctx context.Context, messages []Message, out io.Writer) error {
+	if strings.TrimSpace(c.BaseURL) == "" {
+		return errors.New("chat base URL is not configured")
+	}
+
+	body, err := json.Marshal(completionRequest{Model: c.Model, Messages: messages, Stream: true})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return responseError(resp)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		return copySingle(resp.Body, out)
+	}
+	return copyEvents(resp.Body, out)
+}
+
+func copyEvents(body io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk completionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("decode stream chunk: %w", err)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			if _, err := io.WriteString(out, choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+func copySingle(body io.Reader, out io.Writer) error {
+	var chunk completionChunk
+	if err := json.NewDecoder(body).Decode(&chunk); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	for _, choice := range chunk.Choices {
+		if _, err := io.WriteString(out, choice.Message.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var decoded errorResponse
+	if err := json.Unmarshal(data, &decoded); err == nil && decoded.Error.Message != "" {
+		return fmt.Errorf("chat endpoint returned %s: %s", resp.Status, decoded.Error.Message)
+	}
+	if text := strings.TrimSpace(string(data)); text != "" {
+		return fmt.Errorf("chat endpoint returned %s: %s", resp.Status, text)
+	}
+	return fmt.Errorf("chat endpoint returned %s", resp.Status)
+}
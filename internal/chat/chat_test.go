@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamWritesDeltas(t *testing.T) {
+	var received completionRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{"Hello", ", ", "world"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", part)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := Client{BaseURL: server.URL, Model: "local", APIKey: "secret"}
+	var out bytes.Buffer
+	err := client.Stream(context.Background(), []Message{{Role: "user", Content: "Say hi"}}, &out)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	if out.String() != "Hello, world" {
+		t.Fatalf("expected streamed content, got %q", out.String())
+	}
+	if !received.Stream || received.Model != "local" || len(received.Messages) != 1 || received.Messages[0].Content != "Say hi" {
+		t.Fatalf("unexpected request payload: %+v", received)
+	}
+	if auth != "Bearer secret" {
+		t.Fatalf("expected bearer token, got %q", auth)
+	}
+}
+
+func TestStreamHandlesNonStreamingResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"all at once"}}]}`)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if err := (Client{BaseURL: server.URL + "/v1/"}).Stream(context.Background(), nil, &out); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if out.String() != "all at once" {
+		t.Fatalf("expected full message, got %q", out.String())
+	}
+}
+
+func TestStreamReportsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"invalid api key"}}`)
+	}))
+	defer server.Close()
+
+	err := (Client{BaseURL: server.URL}).Stream(context.Background(), nil, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Fatalf("expected API error message, got %v", err)
+	}
+}
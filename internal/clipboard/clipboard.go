@@ -6,6 +6,8 @@ import (
 	"io"
 	"os/exec"
 	"runtime"
+	"strings"
+	"time"
 )
 
 // ErrUnavailable indicates that no clipboard provider is accessible on this platform.
@@ -22,6 +24,11 @@ type ProviderFunc func(string) error
 // Write implements Provider.
 func (f ProviderFunc) Write(text string) error { return f(text) }
 
+// Reader is implemented by providers that can also read the clipboard.
+type Reader interface {
+	Read() (string, error)
+}
+
 var current Provider = systemProvider{}
 
 // Copy writes text to the clipboard using the active provider.
@@ -29,6 +36,30 @@ func Copy(text string) error {
 	return current.Write(text)
 }
 
+// Paste returns the current clipboard contents using the active provider.
+func Paste() (string, error) {
+	reader, ok := current.(Reader)
+	if !ok {
+		return "", fmt.Errorf("%w: provider cannot read the clipboard", ErrUnavailable)
+	}
+	return reader.Read()
+}
+
+// RestoreAfter waits for d and then writes previous back to the clipboard. The
+// clipboard is left alone when it no longer holds copied, so text copied by the
+// user in the meantime is not clobbered.
+func RestoreAfter(d time.Duration, previous, copied string) error {
+	time.Sleep(d)
+	if text, err := Paste(); err == nil && trimNewlines(text) != trimNewlines(copied) {
+		return nil
+	}
+	return Copy(previous)
+}
+
+func trimNewlines(text string) string {
+	return strings.TrimRight(text, "\r\n")
+}
+
 // SetProvider swaps the clipboard provider, primarily for testing. Passing nil
 // restores the default system-backed provider.
 func SetProvider(p Provider) {
@@ -39,6 +70,93 @@ func SetProvider(p Provider) {
 	current = p
 }
 
+// Command returns a Provider that pipes text into the given command's stdin.
+func Command(bin string, args ...string) Provider {
+	return commandProvider{spec: cmdSpec{name: bin, bin: bin, args: args}}
+}
+
+// CommandWithPaste is like Command but also reads the clipboard from the
+// stdout of paste, whose first element is the executable.
+func CommandWithPaste(bin string, args []string, paste []string) Provider {
+	return commandProvider{spec: cmdSpec{name: bin, bin: bin, args: args, paste: paste}}
+}
+
+// Named returns the built-in provider registered under name. The names "" and
+// "auto" select the platform fallback chain used by default.
+func Named(name string) (Provider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" {
+		return systemProvider{}, nil
+	}
+	for _, spec := range knownCommands {
+		if spec.name == name || spec.bin == name {
+			return commandProvider{spec: spec}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown clipboard provider %q (known: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Names lists the built-in provider names accepted by Named.
+func Names() []string {
+	names := []string{"auto"}
+	for _, spec := range knownCommands {
+		names = append(names, spec.name)
+	}
+	return names
+}
+
+// Status reports whether a clipboard command could be located.
+type Status struct {
+	Name string
+	Bin  string
+	Args []string
+	// Path is the resolved executable, empty when the command was not found.
+	Path string
+	// Auto is true when the provider is part of the platform fallback chain.
+	Auto bool
+}
+
+// Found reports whether the command exists on PATH.
+func (s Status) Found() bool { return s.Path != "" }
+
+// Detect reports the availability of every built-in provider. Providers used
+// by the automatic fallback chain come first, in the order they are tried.
+func Detect() []Status {
+	auto := candidateCommands()
+	statuses := make([]Status, 0, len(knownCommands))
+	seen := make(map[string]struct{})
+	for _, spec := range append(auto, knownCommands...) {
+		if _, ok := seen[spec.name]; ok {
+			continue
+		}
+		seen[spec.name] = struct{}{}
+		statuses = append(statuses, lookup(spec, containsSpec(auto, spec.name)))
+	}
+	return statuses
+}
+
+// DetectCommand reports the availability of an arbitrary command.
+func DetectCommand(bin string, args ...string) Status {
+	return lookup(cmdSpec{name: bin, bin: bin, args: args}, false)
+}
+
+func lookup(spec cmdSpec, auto bool) Status {
+	status := Status{Name: spec.name, Bin: spec.bin, Args: spec.args, Auto: auto}
+	if path, err := exec.LookPath(spec.bin); err == nil {
+		status.Path = path
+	}
+	return status
+}
+
+func containsSpec(specs []cmdSpec, name string) bool {
+	for _, spec := range specs {
+		if spec.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 type systemProvider struct{}
 
 func (systemProvider) Write(text string) error {
@@ -49,54 +167,156 @@ func (systemProvider) Write(text string) error {
 			continue
 		}
 
-		cmd := exec.Command(spec.bin, spec.args...)
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			lastErr = err
-			continue
+		err := runWithInput(spec, text)
+		if err == nil {
+			return nil
 		}
+		var inputErr writeInputError
+		if errors.As(err, &inputErr) {
+			return inputErr.err
+		}
+		lastErr = err
+	}
 
-		if err := cmd.Start(); err != nil {
-			stdin.Close()
-			lastErr = err
+	if lastErr == nil {
+		return ErrUnavailable
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, lastErr)
+}
+
+func (systemProvider) Read() (string, error) {
+	var lastErr error
+	for _, spec := range candidateCommands() {
+		if len(spec.paste) == 0 {
 			continue
 		}
-
-		if _, err := io.WriteString(stdin, text); err != nil {
-			stdin.Close()
-			cmd.Wait()
-			return err
+		if _, err := exec.LookPath(spec.paste[0]); err != nil {
+			lastErr = err
+			continue
 		}
-		stdin.Close()
 
-		if err := cmd.Wait(); err == nil {
-			return nil
+		text, err := runForOutput(spec.paste)
+		if err == nil {
+			return text, nil
 		}
 		lastErr = err
 	}
 
 	if lastErr == nil {
-		return ErrUnavailable
+		return "", ErrUnavailable
 	}
-	return fmt.Errorf("%w: %v", ErrUnavailable, lastErr)
+	return "", fmt.Errorf("%w: %v", ErrUnavailable, lastErr)
+}
+
+type commandProvider struct {
+	spec cmdSpec
+}
+
+func (p commandProvider) Write(text string) error {
+	if _, err := exec.LookPath(p.spec.bin); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	err := runWithInput(p.spec, text)
+	var inputErr writeInputError
+	if errors.As(err, &inputErr) {
+		return inputErr.err
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.spec.bin, err)
+	}
+	return nil
+}
+
+func (p commandProvider) Read() (string, error) {
+	if len(p.spec.paste) == 0 {
+		return "", fmt.Errorf("%w: %s cannot read the clipboard", ErrUnavailable, p.spec.name)
+	}
+	if _, err := exec.LookPath(p.spec.paste[0]); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	text, err := runForOutput(p.spec.paste)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", p.spec.paste[0], err)
+	}
+	return text, nil
+}
+
+// writeInputError marks failures while streaming text to the command, which
+// are not worth retrying with another provider.
+type writeInputError struct{ err error }
+
+func (e writeInputError) Error() string { return e.err.Error() }
+
+func runWithInput(spec cmdSpec, text string) error {
+	cmd := exec.Command(spec.bin, spec.args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		stdin.Close()
+		return err
+	}
+
+	if _, err := io.WriteString(stdin, text); err != nil {
+		stdin.Close()
+		cmd.Wait()
+		return writeInputError{err: err}
+	}
+	stdin.Close()
+
+	return cmd.Wait()
+}
+
+func runForOutput(argv []string) (string, error) {
+	output, err := exec.Command(argv[0], argv[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
 }
 
 type cmdSpec struct {
+	name string
 	bin  string
 	args []string
+	// paste is the full command line used to read the clipboard, if any.
+	paste []string
+}
+
+var knownCommands = []cmdSpec{
+	{name: "pbcopy", bin: "pbcopy", paste: []string{"pbpaste"}},
+	{name: "clip", bin: "clip", paste: []string{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}},
+	{name: "wl-copy", bin: "wl-copy", paste: []string{"wl-paste", "--no-newline"}},
+	{name: "xclip", bin: "xclip", args: []string{"-selection", "clipboard"}, paste: []string{"xclip", "-selection", "clipboard", "-o"}},
+	{name: "xsel", bin: "xsel", args: []string{"-b"}, paste: []string{"xsel", "-b", "-o"}},
+	{name: "termux", bin: "termux-clipboard-set", paste: []string{"termux-clipboard-get"}},
+	{name: "lemonade", bin: "lemonade", args: []string{"copy"}, paste: []string{"lemonade", "paste"}},
+}
+
+func knownCommand(name string) cmdSpec {
+	for _, spec := range knownCommands {
+		if spec.name == name {
+			return spec
+		}
+	}
+	return cmdSpec{name: name, bin: name}
 }
 
 func candidateCommands() []cmdSpec {
 	switch runtime.GOOS {
 	case "darwin":
-		return []cmdSpec{{bin: "pbcopy"}}
+		return []cmdSpec{knownCommand("pbcopy")}
 	case "windows":
-		return []cmdSpec{{bin: "clip"}}
+		return []cmdSpec{knownCommand("clip")}
+	case "android":
+		return []cmdSpec{knownCommand("termux")}
 	default:
 		return []cmdSpec{
-			{bin: "wl-copy"},
-			{bin: "xclip", args: []string{"-selection", "clipboard"}},
-			{bin: "xsel", args: []string{"-b"}},
+			knownCommand("wl-copy"),
+			knownCommand("xclip"),
+			knownCommand("xsel"),
 		}
 	}
 }
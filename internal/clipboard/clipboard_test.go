@@ -1,6 +1,25 @@
 package clipboard
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+type memoryProvider struct {
+	text   string
+	writes int
+}
+
+func (m *memoryProvider) Write(text string) error {
+	m.text = text
+	m.writes++
+	return nil
+}
+
+func (m *memoryProvider) Read() (string, error) { return m.text, nil }
 
 func TestSetProviderOverridesCopy(t *testing.T) {
 	var captured string
@@ -19,3 +38,104 @@ func TestSetProviderOverridesCopy(t *testing.T) {
 		t.Fatalf("expected %q copied, got %q", expected, captured)
 	}
 }
+
+func TestCommandProviderPipesTextToCommand(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	target := filepath.Join(t.TempDir(), "clip.txt")
+
+	provider := Command("sh", "-c", "cat > "+target)
+	if err := provider.Write("from pm"); err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "from pm" {
+		t.Fatalf("expected command to receive %q, got %q", "from pm", string(data))
+	}
+}
+
+func TestNamedRejectsUnknownProvider(t *testing.T) {
+	if _, err := Named("termux"); err != nil {
+		t.Fatalf("expected termux to be a known provider, got %v", err)
+	}
+	if _, err := Named("lemonade"); err != nil {
+		t.Fatalf("expected lemonade to be a known provider, got %v", err)
+	}
+	if _, err := Named("carrier-pigeon"); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+}
+
+func TestDetectListsAutoProvidersFirst(t *testing.T) {
+	statuses := Detect()
+	if len(statuses) != len(knownCommands) {
+		t.Fatalf("expected %d statuses, got %d", len(knownCommands), len(statuses))
+	}
+	auto := candidateCommands()
+	for i, spec := range auto {
+		if statuses[i].Name != spec.name || !statuses[i].Auto {
+			t.Fatalf("expected auto provider %q at position %d, got %+v", spec.name, i, statuses[i])
+		}
+	}
+}
+
+func TestPasteRequiresReader(t *testing.T) {
+	SetProvider(ProviderFunc(func(string) error { return nil }))
+	defer SetProvider(nil)
+
+	if _, err := Paste(); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable for write-only provider, got %v", err)
+	}
+
+	SetProvider(&memoryProvider{text: "current"})
+	text, err := Paste()
+	if err != nil || text != "current" {
+		t.Fatalf("expected %q from Paste, got %q (%v)", "current", text, err)
+	}
+}
+
+func TestRestoreAfterRestoresPreviousContents(t *testing.T) {
+	mem := &memoryProvider{text: "secret prompt\n"}
+	SetProvider(mem)
+	defer SetProvider(nil)
+
+	if err := RestoreAfter(0, "previous", "secret prompt"); err != nil {
+		t.Fatalf("RestoreAfter returned error: %v", err)
+	}
+	if mem.text != "previous" {
+		t.Fatalf("expected clipboard restored to %q, got %q", "previous", mem.text)
+	}
+}
+
+func TestRestoreAfterKeepsNewerClipboardContents(t *testing.T) {
+	mem := &memoryProvider{text: "copied by the user"}
+	SetProvider(mem)
+	defer SetProvider(nil)
+
+	if err := RestoreAfter(0, "previous", "secret prompt"); err != nil {
+		t.Fatalf("RestoreAfter returned error: %v", err)
+	}
+	if mem.writes != 0 || mem.text != "copied by the user" {
+		t.Fatalf("expected newer clipboard contents to be kept, got %q after %d writes", mem.text, mem.writes)
+	}
+}
+
+func TestCommandWithPasteReadsCommandOutput(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	provider := CommandWithPaste("true", nil, []string{"sh", "-c", "printf clipboard"})
+	text, err := provider.(Reader).Read()
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if text != "clipboard" {
+		t.Fatalf("expected %q, got %q", "clipboard", text)
+	}
+}
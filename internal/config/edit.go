@@ -0,0 +1,240 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetFileValue sets the setting name to text, parsed as for ParseValue, in the
+// settings file at path, creating the file if needed. Comments, blank lines
+// and other settings are kept as written. Settings written as inline tables
+// cannot be edited, and values Load would not apply as written, such as an
+// empty default_dir, are reported as errors.
+func SetFileValue(path, name, text string) error {
+	value, err := ParseValue(name, text)
+	if err != nil {
+		return err
+	}
+	literal := FormatValue(value)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	table, leaf := "", name
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		table, leaf = name[:dot], name[dot+1:]
+	}
+	updated := setTOMLValue(string(data), table, leaf, literal)
+
+	// Check the edit took effect rather than trusting the line scanner with
+	// every way TOML can spell a key.
+	tmp := path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(tmp, []byte(updated), mode); err != nil {
+		return err
+	}
+	doc, err := ReadDocument(tmp)
+	if err == nil {
+		if got, ok := doc.Lookup(name); !ok || FormatValue(got) != literal {
+			err = errors.New("the key is written in a form that cannot be edited in place")
+		} else if settings, loadErr := Load(tmp); loadErr != nil {
+			err = loadErr
+		} else if got, _ := Value(settings, name); FormatValue(got) != literal {
+			err = fmt.Errorf("the value would load as %s", FormatValue(got))
+		}
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("%s: cannot set %s: %w", path, name, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// setTOMLValue returns content with leaf = literal set in table ("" for the
+// root table): an existing assignment is replaced in place, keeping its
+// indentation and trailing comment, otherwise the key is added below its
+// commented-out example or at the end of the table, which is appended if
+// missing.
+func setTOMLValue(content, table, leaf, literal string) string {
+	lines := strings.Split(content, "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	current := ""
+	tableStart, tableEnd := -1, len(lines)
+	example := -1
+	if table == "" {
+		tableStart = 0
+	}
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if header, ok := tableHeader(trimmed); ok {
+			if current == table && tableStart >= 0 && tableEnd == len(lines) {
+				tableEnd = i
+			}
+			current = header
+			if current == table {
+				tableStart, tableEnd = i, len(lines)
+			}
+			continue
+		}
+		if commented, ok := strings.CutPrefix(trimmed, "#"); ok {
+			key, _, isAssignment := strings.Cut(commented, "=")
+			if current == table && isAssignment && unquoteKey(key) == leaf && example < 0 {
+				example = i
+			}
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+
+		end, comment := statementEnd(lines, i)
+		key, _, _ := strings.Cut(trimmed, "=")
+		key = unquoteKey(key)
+		if (current == table && key == leaf) || (current == "" && table != "" && key == table+"."+leaf) {
+			indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+			line := indent + leaf + " = " + literal
+			if current == "" && table != "" {
+				line = indent + table + "." + leaf + " = " + literal
+			}
+			if comment != "" {
+				line += " " + comment
+			}
+			lines = append(lines[:i], append([]string{line}, lines[end+1:]...)...)
+			return strings.Join(lines, "\n")
+		}
+		i = end
+	}
+
+	line := leaf + " = " + literal
+	if example >= 0 {
+		lines = append(lines[:example+1], append([]string{line}, lines[example+1:]...)...)
+		return strings.Join(lines, "\n")
+	}
+	if tableStart < 0 {
+		for len(lines) > 0 && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+table+"]", line, "")
+		return strings.Join(lines, "\n")
+	}
+
+	// Insert after the table's last statement, before any blank lines and
+	// comments that lead into the next table.
+	at := tableEnd
+	for at > tableStart && at > 0 {
+		trimmed := strings.TrimSpace(lines[at-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		at--
+	}
+	if table == "" && at == 0 && tableEnd < len(lines) {
+		line += "\n"
+	}
+	lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+	if len(lines) > 0 && lines[len(lines)-1] != "" {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableHeader parses a [table] line. Array tables are reported with their
+// brackets so that they never match a plain table.
+func tableHeader(trimmed string) (string, bool) {
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	array := strings.HasPrefix(trimmed, "[[")
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	end := strings.Index(trimmed, closing)
+	if end < 0 {
+		return "", false
+	}
+	inner := strings.Trim(trimmed[:end], "[")
+	parts := strings.Split(inner, ".")
+	for i, part := range parts {
+		parts[i] = unquoteKey(part)
+	}
+	name := strings.Join(parts, ".")
+	if array {
+		return "[[" + name + "]]", true
+	}
+	return name, true
+}
+
+func unquoteKey(key string) string {
+	key = strings.TrimSpace(key)
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+// statementEnd returns the last line of the key/value statement starting at
+// lines[start], following arrays, inline tables and multi-line strings, and
+// the comment closing a single-line statement.
+func statementEnd(lines []string, start int) (int, string) {
+	depth := 0
+	quote := ""
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		for j := 0; j < len(line); j++ {
+			rest := line[j:]
+			switch {
+			case quote != "":
+				if quote == `"` || quote == `"""` {
+					if line[j] == '\\' {
+						j++
+						continue
+					}
+				}
+				if strings.HasPrefix(rest, quote) {
+					j += len(quote) - 1
+					quote = ""
+				}
+			case strings.HasPrefix(rest, `"""`), strings.HasPrefix(rest, `'''`):
+				quote = rest[:3]
+				j += 2
+			case line[j] == '"' || line[j] == '\'':
+				quote = line[j : j+1]
+			case line[j] == '[' || line[j] == '{':
+				depth++
+			case line[j] == ']' || line[j] == '}':
+				depth--
+			case line[j] == '#':
+				if depth <= 0 && i == start {
+					return i, strings.TrimSpace(rest)
+				}
+				j = len(line)
+			}
+		}
+		if quote == `"` || quote == `'` {
+			quote = "" // single-line strings cannot continue
+		}
+		if depth <= 0 && quote == "" {
+			return i, ""
+		}
+	}
+	return len(lines) - 1, ""
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFileValueKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.toml")
+	content := `# my prompts
+default_dir = [
+  "~/prompts", # personal
+  "~/work",
+]
+
+[fuzzy_search]
+# keep it short
+max_results = 20 # tuned
+
+[chat]
+base_url = "http://localhost:8080/v1"
+
+# Targets follow
+[targets.claude]
+command = "claude"
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, set := range [][2]string{
+		{"fuzzy_search.max_results", "5"},
+		{"default_dir", "~/a,~/b"},
+		{"chat.model", "llama3"},
+		{"cache_dir", "~/.cache/pm"},
+		{"targets.claude.args", "-p"},
+		{"ui.truncate_length", "80"},
+	} {
+		if err := SetFileValue(path, set[0], set[1]); err != nil {
+			t.Fatalf("SetFileValue(%s) error = %v", set[0], err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := `# my prompts
+default_dir = ["~/a", "~/b"]
+cache_dir = "~/.cache/pm"
+
+[fuzzy_search]
+# keep it short
+max_results = 5 # tuned
+
+[chat]
+base_url = "http://localhost:8080/v1"
+model = "llama3"
+
+# Targets follow
+[targets.claude]
+command = "claude"
+args = ["-p"]
+
+[ui]
+truncate_length = 80
+`
+	if string(data) != want {
+		t.Fatalf("unexpected file:\n%s\nwant:\n%s", data, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the file mode to be kept, got %v", info.Mode())
+	}
+}
+
+func TestSetFileValueCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pmc", "settings.toml")
+	if err := SetFileValue(path, "file_system.use_gitignore", "true"); err != nil {
+		t.Fatalf("SetFileValue() error = %v", err)
+	}
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !settings.FileSystem.UseGitignore {
+		t.Fatal("expected use_gitignore to be set")
+	}
+}
+
+func TestSetFileValueRejectsInlineTables(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.toml")
+	content := "chat = { model = \"a\" }\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	err := SetFileValue(path, "chat.model", "b")
+	if err == nil || !strings.Contains(err.Error(), "cannot set chat.model") {
+		t.Fatalf("expected an in-place edit error, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Fatalf("expected the file to be left alone, got %q", data)
+	}
+}
+
+func TestSetFileValueMatchesLoadedValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.toml")
+	for _, set := range [][2]string{
+		{"file_system.max_file_size_kb", "0"},
+		{"ui.truncate_length", "-5"},
+		{"file_system.ignore_patterns", ""},
+	} {
+		if err := SetFileValue(path, set[0], set[1]); err != nil {
+			t.Fatalf("SetFileValue(%s) error = %v", set[0], err)
+		}
+	}
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if settings.FileSystem.MaxFileSizeKB != 0 || settings.UI.TruncateLength != -5 || len(settings.FileSystem.IgnorePatterns) != 0 {
+		t.Fatalf("expected the written values to load, got %+v and %+v", settings.FileSystem, settings.UI)
+	}
+
+	before, _ := os.ReadFile(path)
+	err = SetFileValue(path, "default_dir", "")
+	if err == nil || !strings.Contains(err.Error(), "would load as") {
+		t.Fatalf("expected an empty default_dir to be rejected, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(before) {
+		t.Fatalf("expected the file to be left alone, got %q", data)
+	}
+}
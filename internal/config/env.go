@@ -0,0 +1,186 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ConfigEnv names the environment variable selecting the settings file.
+	ConfigEnv = "PM_CONFIG"
+	// EnvPrefix starts the environment variables that override settings,
+	// such as PM_FUZZY_SEARCH_MAX_RESULTS for fuzzy_search.max_results.
+	EnvPrefix = "PM_"
+)
+
+// targetsEnvPrefix starts PM_TARGETS_<NAME>_<FIELD> variables.
+const targetsEnvPrefix = EnvPrefix + "TARGETS_"
+
+// Key is a setting addressed by its dotted TOML key, such as
+// "file_system.max_file_size_kb".
+type Key struct {
+	Name  string
+	index []int
+}
+
+// Env returns the environment variable overriding the key.
+func (k Key) Env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(k.Name, ".", "_"))
+}
+
+// Set parses text into the key's value in settings. Lists are comma
+// separated and tables are written as comma separated key=value pairs.
+func (k Key) Set(settings *Settings, text string) error {
+	return setValue(reflect.ValueOf(settings).Elem().FieldByIndex(k.index), text)
+}
+
+// Keys lists the settings holding a string, number, boolean, list of strings
+// or table of strings, in file order. The sources, packs and targets tables
+// are not keys.
+func Keys() []Key {
+	return collectKeys(reflect.TypeOf(Settings{}), "", nil)
+}
+
+func collectKeys(t reflect.Type, prefix string, index []int) []Key {
+	var keys []Key
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := append(append([]int(nil), index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, collectKeys(field.Type, prefix+name+".", path)...)
+			continue
+		}
+		if isValueType(field.Type) {
+			keys = append(keys, Key{Name: prefix + name, index: path})
+		}
+	}
+	return keys
+}
+
+func isValueType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func setValue(v reflect.Value, text string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", text)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", text)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(splitList(text)))
+	case reflect.Map:
+		table := make(map[string]string)
+		for _, pair := range splitList(text) {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected key=value pairs, got %q", pair)
+			}
+			table[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		v.Set(reflect.ValueOf(table))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ApplyEnv overrides settings with the PM_* variables in environ, given as
+// NAME=value pairs like os.Environ. Every key has a variable named after it;
+// PM_TARGETS_<NAME>_COMMAND, _ARGS and _INPUT add or change the target
+// <name>, in lower case. Empty variables are ignored.
+func ApplyEnv(settings Settings, environ []string) (Settings, error) {
+	env := make(map[string]string)
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if ok && value != "" && strings.HasPrefix(name, EnvPrefix) {
+			env[name] = value
+		}
+	}
+
+	for _, key := range Keys() {
+		value, ok := env[key.Env()]
+		if !ok {
+			continue
+		}
+		if err := key.Set(&settings, value); err != nil {
+			return settings, fmt.Errorf("%s: %w", key.Env(), err)
+		}
+	}
+
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if strings.HasPrefix(name, targetsEnvPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := applyTargetEnv(&settings, name, env[name]); err != nil {
+			return settings, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return settings, nil
+}
+
+func applyTargetEnv(settings *Settings, name, value string) error {
+	rest := strings.TrimPrefix(name, targetsEnvPrefix)
+	t := reflect.TypeOf(TargetSettings{})
+	for i := 0; i < t.NumField(); i++ {
+		suffix := "_" + strings.ToUpper(t.Field(i).Tag.Get("toml"))
+		target, ok := strings.CutSuffix(rest, suffix)
+		if !ok || target == "" {
+			continue
+		}
+		targets := maps.Clone(settings.Targets)
+		if targets == nil {
+			targets = make(map[string]TargetSettings)
+		}
+		settings.Targets = targets
+
+		entry := targets[strings.ToLower(target)]
+		if err := setValue(reflect.ValueOf(&entry).Elem().Field(i), value); err != nil {
+			return err
+		}
+		targets[strings.ToLower(target)] = entry
+		return nil
+	}
+	return fmt.Errorf("expected a name followed by _COMMAND, _ARGS or _INPUT")
+}
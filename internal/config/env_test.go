@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyEnvOverridesSettings(t *testing.T) {
+	environ := []string{
+		"PM_DEFAULT_DIR=~/a, ~/b",
+		"PM_FILE_SYSTEM_MAX_FILE_SIZE_KB=64",
+		"PM_FILE_SYSTEM_USE_GITIGNORE=true",
+		"PM_CHAT_MODEL=llama3",
+		"PM_DISCOVERY_MARKERS=type=prompt",
+		"PM_CLIPBOARD_PROVIDER=",
+		"PM_TARGETS_CLAUDE_COMMAND=claude",
+		"PM_TARGETS_CLAUDE_ARGS=-p",
+		"PATH=/usr/bin",
+	}
+
+	defaults := Defaults()
+	settings, err := ApplyEnv(defaults, environ)
+	if err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+
+	if strings.Join(settings.DefaultDirs, "|") != "~/a|~/b" {
+		t.Fatalf("expected both default dirs, got %v", settings.DefaultDirs)
+	}
+	if settings.FileSystem.MaxFileSizeKB != 64 || !settings.FileSystem.UseGitignore {
+		t.Fatalf("unexpected file system settings %+v", settings.FileSystem)
+	}
+	if settings.Chat.Model != "llama3" || settings.Discovery.Markers["type"] != "prompt" {
+		t.Fatalf("unexpected chat or discovery settings %+v %+v", settings.Chat, settings.Discovery)
+	}
+	if settings.Clipboard.Provider != "auto" {
+		t.Fatalf("expected empty variables to be ignored, got %q", settings.Clipboard.Provider)
+	}
+	target := settings.Targets["claude"]
+	if target.Command != "claude" || len(target.Args) != 1 || target.Args[0] != "-p" {
+		t.Fatalf("unexpected target %+v", target)
+	}
+	if defaults.FileSystem.MaxFileSizeKB != 128 {
+		t.Fatal("expected the input settings to be left alone")
+	}
+}
+
+func TestApplyEnvRejectsInvalidValues(t *testing.T) {
+	_, err := ApplyEnv(Defaults(), []string{"PM_FUZZY_SEARCH_MAX_RESULTS=lots"})
+	if err == nil || !strings.HasPrefix(err.Error(), "PM_FUZZY_SEARCH_MAX_RESULTS: ") {
+		t.Fatalf("expected an error naming the variable, got %v", err)
+	}
+}
+
+func TestKeysCoverSettings(t *testing.T) {
+	var names []string
+	for _, key := range Keys() {
+		names = append(names, key.Name)
+	}
+	joined := strings.Join(names, " ")
+	for _, want := range []string{"default_dir", "cache_dir", "file_system.split_sections", "clipboard.paste_args", "discovery.markers", "default_target"} {
+		if !strings.Contains(" "+joined+" ", " "+want+" ") {
+			t.Errorf("expected key %s in %v", want, names)
+		}
+	}
+	for _, unwanted := range []string{"sources", "packs", "targets"} {
+		if strings.Contains(" "+joined+" ", " "+unwanted+" ") {
+			t.Errorf("unexpected key %s", unwanted)
+		}
+	}
+}
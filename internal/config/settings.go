@@ -1,19 +1,41 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
 // Settings represents persisted configuration for the CLI.
 type Settings struct {
-	DefaultDirs []string            `toml:"default_dir"`
-	CacheDir    string              `toml:"cache_dir"`
-	FileSystem  FileSystemSettings  `toml:"file_system"`
-	FuzzySearch FuzzySearchSettings `toml:"fuzzy_search"`
-	UI          UISettings          `toml:"ui"`
+	// DefaultDirs is the flat list of prompt directories. Sources supersede it
+	// but both are loaded; see PromptSources.
+	DefaultDirs []string         `toml:"default_dir"`
+	Sources     []SourceSettings `toml:"sources"`
+	// ProjectSources come from the current project; see LoadProject.
+	ProjectSources []SourceSettings    `toml:"-"`
+	CacheDir       string              `toml:"cache_dir"`
+	FileSystem     FileSystemSettings  `toml:"file_system"`
+	FuzzySearch    FuzzySearchSettings `toml:"fuzzy_search"`
+	UI             UISettings          `toml:"ui"`
+	Clipboard      ClipboardSettings   `toml:"clipboard"`
+	Chat           ChatSettings        `toml:"chat"`
+	Discovery      DiscoverySettings   `toml:"discovery"`
+	// DefaultTarget names the entry in Targets used by the picker keybinding.
+	DefaultTarget string                    `toml:"default_target"`
+	Targets       map[string]TargetSettings `toml:"targets"`
+	// Packs are prompt packs downloaded over HTTP by `pm sync`.
+	Packs []PackSettings `toml:"packs"`
+	// TrustedProjects are project roots whose settings files may set every
+	// key; see LoadProject.
+	TrustedProjects []string `toml:"trusted_projects"`
 }
 
 // FileSystemSettings describe filesystem discovery behaviour.
@@ -21,6 +43,36 @@ type FileSystemSettings struct {
 	Extensions     []string `toml:"extensions"`
 	IgnorePatterns []string `toml:"ignore_patterns"`
 	MaxFileSizeKB  int      `toml:"max_file_size_kb"`
+	// FollowSymlinks descends into symlinked directories.
+	FollowSymlinks bool `toml:"follow_symlinks"`
+	// UseGitignore honours .gitignore files in addition to .pmignore.
+	UseGitignore bool `toml:"use_gitignore"`
+	// SplitSections loads each "## " section of a file as its own prompt.
+	SplitSections bool `toml:"split_sections"`
+}
+
+// SourceSettings describe one prompt directory or git source. Sources with a
+// higher Priority are loaded first and win name clashes. Extensions and
+// MaxFileSizeKB replace the file_system values for this source when set;
+// IgnorePatterns are added to them.
+type SourceSettings struct {
+	Path           string   `toml:"path"`
+	Label          string   `toml:"label"`
+	Priority       int      `toml:"priority"`
+	Enabled        bool     `toml:"enabled"`
+	ReadOnly       bool     `toml:"read_only"`
+	Extensions     []string `toml:"extensions"`
+	IgnorePatterns []string `toml:"ignore_patterns"`
+	MaxFileSizeKB  int      `toml:"max_file_size_kb"`
+}
+
+// DiscoverySettings restrict loading to notes that are prompts: files under
+// Paths, or carrying one of the front matter Markers or Tags. Everything is
+// loaded when all three are empty.
+type DiscoverySettings struct {
+	Paths   []string          `toml:"paths"`
+	Markers map[string]string `toml:"markers"`
+	Tags    []string          `toml:"tags"`
 }
 
 // FuzzySearchSettings describe search behaviour.
@@ -33,96 +85,330 @@ type UISettings struct {
 	TruncateLength int `toml:"truncate_length"`
 }
 
+// ClipboardSettings select how text is copied to the clipboard. Command takes
+// precedence over Provider when both are set; PasteCommand lets a custom
+// command read the clipboard back.
+type ClipboardSettings struct {
+	Provider     string   `toml:"provider"`
+	Command      string   `toml:"command"`
+	Args         []string `toml:"args"`
+	PasteCommand string   `toml:"paste_command"`
+	PasteArgs    []string `toml:"paste_args"`
+}
+
+// ChatSettings point `pm run` at an OpenAI-compatible chat completions server.
+// The API key is read from the environment variable named by APIKeyEnv.
+type ChatSettings struct {
+	BaseURL   string `toml:"base_url"`
+	Model     string `toml:"model"`
+	APIKeyEnv string `toml:"api_key_env"`
+}
+
+// TargetSettings describe an external program a picked prompt can be handed
+// to. Input is "stdin" (the default) or "arg" to append the prompt as the last
+// argument.
+type TargetSettings struct {
+	Command string   `toml:"command"`
+	Args    []string `toml:"args"`
+	Input   string   `toml:"input"`
+}
+
+// PackSettings describe a prompt pack: a tarball or JSON index fetched from
+// URL. When SHA256 or PublicKey is set the download must match the digest or
+// carry a valid signature, fetched from SignatureURL (URL + ".minisig" by
+// default).
+type PackSettings struct {
+	Name         string `toml:"name"`
+	URL          string `toml:"url"`
+	SHA256       string `toml:"sha256"`
+	PublicKey    string `toml:"public_key"`
+	SignatureURL string `toml:"signature_url"`
+}
+
+// rawSettings mirrors Settings with optional values, so that a layer
+// overrides exactly the keys it sets, including with zero or empty values,
+// like the environment does.
 type rawSettings struct {
-	DefaultDirs interface{}         `toml:"default_dir"`
-	CacheDir    string              `toml:"cache_dir"`
-	FileSystem  FileSystemSettings  `toml:"file_system"`
-	FuzzySearch FuzzySearchSettings `toml:"fuzzy_search"`
-	UI          UISettings          `toml:"ui"`
+	DefaultDirs   interface{}               `toml:"default_dir"`
+	Sources       []rawSourceSettings       `toml:"sources"`
+	CacheDir      *string                   `toml:"cache_dir"`
+	FileSystem    rawFileSystemSettings     `toml:"file_system"`
+	FuzzySearch   rawFuzzySearchSettings    `toml:"fuzzy_search"`
+	UI            rawUISettings             `toml:"ui"`
+	Clipboard     rawClipboardSettings      `toml:"clipboard"`
+	Chat          rawChatSettings           `toml:"chat"`
+	Discovery     rawDiscoverySettings      `toml:"discovery"`
+	DefaultTarget *string                   `toml:"default_target"`
+	Targets       map[string]TargetSettings `toml:"targets"`
+	Packs         []PackSettings            `toml:"packs"`
+	// TrustedProjects is only read from the user's settings.
+	TrustedProjects *[]string `toml:"trusted_projects"`
+}
+
+type rawFileSystemSettings struct {
+	Extensions     *[]string `toml:"extensions"`
+	IgnorePatterns *[]string `toml:"ignore_patterns"`
+	MaxFileSizeKB  *int      `toml:"max_file_size_kb"`
+	FollowSymlinks *bool     `toml:"follow_symlinks"`
+	UseGitignore   *bool     `toml:"use_gitignore"`
+	SplitSections  *bool     `toml:"split_sections"`
 }
 
-// DefaultPath returns the default configuration path for this CLI.
+type rawFuzzySearchSettings struct {
+	MaxResults *int `toml:"max_results"`
+}
+
+type rawUISettings struct {
+	TruncateLength *int `toml:"truncate_length"`
+}
+
+type rawClipboardSettings struct {
+	Provider     *string   `toml:"provider"`
+	Command      *string   `toml:"command"`
+	Args         *[]string `toml:"args"`
+	PasteCommand *string   `toml:"paste_command"`
+	PasteArgs    *[]string `toml:"paste_args"`
+}
+
+type rawChatSettings struct {
+	BaseURL   *string `toml:"base_url"`
+	Model     *string `toml:"model"`
+	APIKeyEnv *string `toml:"api_key_env"`
+}
+
+type rawDiscoverySettings struct {
+	Paths   *[]string          `toml:"paths"`
+	Markers *map[string]string `toml:"markers"`
+	Tags    *[]string          `toml:"tags"`
+}
+
+// rawSourceSettings keeps Enabled optional so that sources default to on.
+type rawSourceSettings struct {
+	Path           string   `toml:"path"`
+	Label          string   `toml:"label"`
+	Priority       int      `toml:"priority"`
+	Enabled        *bool    `toml:"enabled"`
+	ReadOnly       bool     `toml:"read_only"`
+	Extensions     []string `toml:"extensions"`
+	IgnorePatterns []string `toml:"ignore_patterns"`
+	MaxFileSizeKB  int      `toml:"max_file_size_kb"`
+}
+
+// DefaultPath returns the default configuration path for this CLI:
+// settings.toml in $XDG_CONFIG_HOME/pmc, or in ~/.config/pmc.
 func DefaultPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "pmc", "settings.toml")
+	}
 	if home, err := os.UserHomeDir(); err == nil && home != "" {
 		return filepath.Join(home, ".config", "pmc", "settings.toml")
 	}
 	return "config/settings.toml"
 }
 
-// Load reads settings from the provided path. Missing or malformed files fall back to defaults.
-func Load(path string) Settings {
-	defaults := Settings{
+// Defaults returns the settings used for values no configuration sets.
+func Defaults() Settings {
+	return Settings{
 		DefaultDirs: []string{"~/prompts"},
 		CacheDir:    "~/.cache/pmc",
 		FileSystem: FileSystemSettings{
-			Extensions:     []string{".md", ".txt"},
+			Extensions:     []string{".md", ".txt", ".prompt.yaml", ".prompt.yml", ".prompt.json"},
 			IgnorePatterns: []string{".DS_Store"},
 			MaxFileSizeKB:  128,
 		},
 		FuzzySearch: FuzzySearchSettings{MaxResults: 20},
 		UI:          UISettings{TruncateLength: 120},
+		Clipboard:   ClipboardSettings{Provider: "auto"},
+		Chat: ChatSettings{
+			BaseURL:   "http://localhost:8080/v1",
+			APIKeyEnv: "OPENAI_API_KEY",
+		},
 	}
+}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return defaults
-	}
+// Load reads settings from the provided path on top of Defaults. A missing
+// file yields the defaults; a malformed one is an error pointing at the
+// offending line and column.
+func Load(path string) (Settings, error) {
+	defaults := Defaults()
 
-	var raw rawSettings
-	if err := toml.Unmarshal(data, &raw); err != nil {
-		return defaults
+	raw, err := readRaw(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, err
 	}
 
 	settings := defaults
 
 	// Handle default_dir which can be string or []string
-	defaultDirs := parseStringOrSlice(raw.DefaultDirs)
+	defaultDirs, err := parseStringOrSlice(raw.DefaultDirs)
+	if err != nil {
+		return defaults, fmt.Errorf("%s: default_dir %w", path, err)
+	}
 	if len(defaultDirs) > 0 {
 		settings.DefaultDirs = defaultDirs
+	} else if len(raw.Sources) > 0 {
+		// Configured sources replace the default directory.
+		settings.DefaultDirs = nil
+	}
+	settings.Sources = raw.sources()
+	settings.apply(raw)
+
+	return settings, nil
+}
+
+// readRaw parses the settings file at path, reporting syntax and type errors
+// as path:line:column.
+func readRaw(path string) (rawSettings, error) {
+	var raw rawSettings
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return raw, err
+	}
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			row, column := decodeErr.Position()
+			return raw, fmt.Errorf("%s:%d:%d: %s", path, row, column, strings.TrimPrefix(decodeErr.Error(), "toml: "))
+		}
+		return raw, fmt.Errorf("%s: %w", path, err)
 	}
+	return raw, nil
+}
 
-	if raw.CacheDir != "" {
-		settings.CacheDir = raw.CacheDir
+// apply overlays the values set in raw, other than the prompt directories.
+// Targets and packs are added to those already configured. A clipboard
+// command replaces the earlier arguments along with the command.
+func (s *Settings) apply(raw rawSettings) {
+	set(&s.CacheDir, raw.CacheDir)
+	set(&s.FileSystem.Extensions, raw.FileSystem.Extensions)
+	set(&s.FileSystem.IgnorePatterns, raw.FileSystem.IgnorePatterns)
+	set(&s.FileSystem.MaxFileSizeKB, raw.FileSystem.MaxFileSizeKB)
+	set(&s.FileSystem.FollowSymlinks, raw.FileSystem.FollowSymlinks)
+	set(&s.FileSystem.UseGitignore, raw.FileSystem.UseGitignore)
+	set(&s.FileSystem.SplitSections, raw.FileSystem.SplitSections)
+	set(&s.FuzzySearch.MaxResults, raw.FuzzySearch.MaxResults)
+	set(&s.UI.TruncateLength, raw.UI.TruncateLength)
+	set(&s.Clipboard.Provider, raw.Clipboard.Provider)
+	if raw.Clipboard.Command != nil {
+		s.Clipboard.Args = nil
 	}
-	if len(raw.FileSystem.Extensions) > 0 {
-		settings.FileSystem.Extensions = raw.FileSystem.Extensions
+	set(&s.Clipboard.Command, raw.Clipboard.Command)
+	set(&s.Clipboard.Args, raw.Clipboard.Args)
+	if raw.Clipboard.PasteCommand != nil {
+		s.Clipboard.PasteArgs = nil
 	}
-	if len(raw.FileSystem.IgnorePatterns) > 0 {
-		settings.FileSystem.IgnorePatterns = raw.FileSystem.IgnorePatterns
+	set(&s.Clipboard.PasteCommand, raw.Clipboard.PasteCommand)
+	set(&s.Clipboard.PasteArgs, raw.Clipboard.PasteArgs)
+	set(&s.Chat.BaseURL, raw.Chat.BaseURL)
+	set(&s.Chat.Model, raw.Chat.Model)
+	set(&s.Chat.APIKeyEnv, raw.Chat.APIKeyEnv)
+	set(&s.Discovery.Paths, raw.Discovery.Paths)
+	set(&s.Discovery.Markers, raw.Discovery.Markers)
+	set(&s.Discovery.Tags, raw.Discovery.Tags)
+	set(&s.DefaultTarget, raw.DefaultTarget)
+	if len(raw.Targets) > 0 {
+		targets := maps.Clone(s.Targets)
+		if targets == nil {
+			targets = make(map[string]TargetSettings, len(raw.Targets))
+		}
+		maps.Copy(targets, raw.Targets)
+		s.Targets = targets
 	}
-	if raw.FileSystem.MaxFileSizeKB > 0 {
-		settings.FileSystem.MaxFileSizeKB = raw.FileSystem.MaxFileSizeKB
+	if len(raw.Packs) > 0 {
+		s.Packs = append(append([]PackSettings(nil), s.Packs...), raw.Packs...)
 	}
-	if raw.FuzzySearch.MaxResults > 0 {
-		settings.FuzzySearch.MaxResults = raw.FuzzySearch.MaxResults
+	set(&s.TrustedProjects, raw.TrustedProjects)
+}
+
+// set copies value into field when the layer sets it.
+func set[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
 	}
-	if raw.UI.TruncateLength > 0 {
-		settings.UI.TruncateLength = raw.UI.TruncateLength
+}
+
+// promptSettings keeps the settings that only shape which prompts load and
+// how they are shown, dropping those that run commands, send requests or
+// choose where files are written.
+func (raw rawSettings) promptSettings() rawSettings {
+	return rawSettings{
+		DefaultDirs: raw.DefaultDirs,
+		Sources:     raw.Sources,
+		FileSystem:  raw.FileSystem,
+		FuzzySearch: raw.FuzzySearch,
+		UI:          raw.UI,
+		Discovery:   raw.Discovery,
 	}
+}
 
-	return settings
+// sources converts the [[sources]] tables, enabling those that do not say.
+func (raw rawSettings) sources() []SourceSettings {
+	var sources []SourceSettings
+	for _, source := range raw.Sources {
+		sources = append(sources, SourceSettings{
+			Path:           source.Path,
+			Label:          source.Label,
+			Priority:       source.Priority,
+			Enabled:        source.Enabled == nil || *source.Enabled,
+			ReadOnly:       source.ReadOnly,
+			Extensions:     source.Extensions,
+			IgnorePatterns: source.IgnorePatterns,
+			MaxFileSizeKB:  source.MaxFileSizeKB,
+		})
+	}
+	return sources
+}
+
+// PromptSources returns the enabled prompt sources in load order: the
+// project sources, then the default_dir entries followed by [[sources]],
+// each group sorted by descending priority.
+func (s Settings) PromptSources() []SourceSettings {
+	global := make([]SourceSettings, 0, len(s.DefaultDirs)+len(s.Sources))
+	for _, dir := range s.DefaultDirs {
+		global = append(global, SourceSettings{Path: dir, Enabled: true})
+	}
+	global = append(global, s.Sources...)
+	return append(enabledSources(s.ProjectSources), enabledSources(global)...)
+}
+
+func enabledSources(all []SourceSettings) []SourceSettings {
+	var sources []SourceSettings
+	for _, source := range all {
+		if source.Enabled && source.Path != "" {
+			sources = append(sources, source)
+		}
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority > sources[j].Priority
+	})
+	return sources
 }
 
 // parseStringOrSlice handles values that can be either a string or an array of strings.
-func parseStringOrSlice(v interface{}) []string {
+func parseStringOrSlice(v interface{}) ([]string, error) {
 	if v == nil {
-		return nil
+		return nil, nil
 	}
 
 	switch val := v.(type) {
 	case string:
-		return []string{val}
+		return []string{val}, nil
 	case []interface{}:
 		result := make([]string, 0, len(val))
 		for _, item := range val {
-			if str, ok := item.(string); ok {
-				result = append(result, str)
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a string or an array of strings, found %T", item)
 			}
+			result = append(result, str)
 		}
-		return result
+		return result, nil
 	case []string:
-		return val
+		return val, nil
 	}
 
-	return nil
+	return nil, fmt.Errorf("must be a string or an array of strings, found %T", v)
 }
@@ -3,11 +3,15 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestLoadDefaultsWhenMissing(t *testing.T) {
-	settings := Load("non-existent.toml")
+	settings, err := Load("non-existent.toml")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
 
 	if len(settings.DefaultDirs) == 0 {
 		t.Fatal("expected default directories to be populated")
@@ -28,6 +32,9 @@ default_dir = "custom"
 extensions = [".md"]
 ignore_patterns = ["*.tmp"]
 max_file_size_kb = 42
+follow_symlinks = true
+use_gitignore = true
+split_sections = true
 
 [fuzzy_search]
 max_results = 5
@@ -37,7 +44,10 @@ max_results = 5
 		t.Fatalf("WriteFile() error = %v", err)
 	}
 
-	settings := Load(path)
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
 
 	if len(settings.DefaultDirs) != 1 || settings.DefaultDirs[0] != "custom" {
 		t.Fatalf("expected default dir 'custom', got %v", settings.DefaultDirs)
@@ -47,7 +57,299 @@ max_results = 5
 		t.Fatalf("expected MaxFileSizeKB 42, got %d", settings.FileSystem.MaxFileSizeKB)
 	}
 
+	if !settings.FileSystem.FollowSymlinks || !settings.FileSystem.UseGitignore || !settings.FileSystem.SplitSections {
+		t.Fatal("expected FollowSymlinks, UseGitignore and SplitSections to be enabled")
+	}
+
 	if settings.FuzzySearch.MaxResults != 5 {
 		t.Fatalf("expected MaxResults 5, got %d", settings.FuzzySearch.MaxResults)
 	}
 }
+
+func TestLoadAppliesZeroAndEmptyValues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.toml")
+
+	content := []byte(`
+cache_dir = ""
+
+[file_system]
+max_file_size_kb = 0
+ignore_patterns = []
+
+[fuzzy_search]
+max_results = 0
+
+[clipboard]
+provider = ""
+`)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	env, err := ApplyEnv(Defaults(), []string{
+		"PM_FILE_SYSTEM_MAX_FILE_SIZE_KB=0",
+		"PM_FUZZY_SEARCH_MAX_RESULTS=0",
+	})
+	if err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if settings.FileSystem.MaxFileSizeKB != env.FileSystem.MaxFileSizeKB || settings.FuzzySearch.MaxResults != env.FuzzySearch.MaxResults {
+		t.Fatalf("expected the file to set zero like the environment, got %+v and %+v", settings.FileSystem, settings.FuzzySearch)
+	}
+	if settings.CacheDir != "" || len(settings.FileSystem.IgnorePatterns) != 0 || settings.Clipboard.Provider != "" {
+		t.Fatalf("expected empty values to apply, got %q, %v and %q", settings.CacheDir, settings.FileSystem.IgnorePatterns, settings.Clipboard.Provider)
+	}
+	if len(settings.FileSystem.Extensions) == 0 || settings.UI.TruncateLength != 120 {
+		t.Fatalf("expected unset keys to keep their defaults, got %+v", settings)
+	}
+}
+
+func TestLoadParsesClipboardSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.toml")
+
+	content := []byte(`
+[clipboard]
+command = "lemonade"
+args = ["copy"]
+`)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if settings.Clipboard.Provider != "auto" {
+		t.Fatalf("expected default provider auto, got %q", settings.Clipboard.Provider)
+	}
+	if settings.Clipboard.Command != "lemonade" || len(settings.Clipboard.Args) != 1 || settings.Clipboard.Args[0] != "copy" {
+		t.Fatalf("unexpected clipboard command settings: %+v", settings.Clipboard)
+	}
+}
+
+func TestLoadParsesTargets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.toml")
+
+	content := []byte(`
+default_target = "codex"
+
+[targets.codex]
+command = "codex"
+input = "arg"
+
+[targets.llm]
+command = "llm"
+args = ["-m", "gpt-4o"]
+`)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if settings.DefaultTarget != "codex" {
+		t.Fatalf("expected default target codex, got %q", settings.DefaultTarget)
+	}
+	codex, ok := settings.Targets["codex"]
+	if !ok || codex.Command != "codex" || codex.Input != "arg" {
+		t.Fatalf("unexpected codex target: %+v", codex)
+	}
+	llm := settings.Targets["llm"]
+	if len(llm.Args) != 2 || llm.Args[1] != "gpt-4o" {
+		t.Fatalf("unexpected llm target args: %+v", llm.Args)
+	}
+}
+
+func TestLoadParsesDiscoverySettings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.toml")
+
+	content := []byte(`
+[discovery]
+paths = ["Prompts/"]
+tags = ["prompt"]
+
+[discovery.markers]
+type = "prompt"
+`)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(settings.Discovery.Paths) != 1 || settings.Discovery.Paths[0] != "Prompts/" {
+		t.Fatalf("unexpected discovery paths: %v", settings.Discovery.Paths)
+	}
+	if settings.Discovery.Markers["type"] != "prompt" {
+		t.Fatalf("unexpected discovery markers: %v", settings.Discovery.Markers)
+	}
+	if len(settings.Discovery.Tags) != 1 || settings.Discovery.Tags[0] != "prompt" {
+		t.Fatalf("unexpected discovery tags: %v", settings.Discovery.Tags)
+	}
+}
+
+func TestLoadParsesPacks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.toml")
+
+	content := []byte(`
+[[packs]]
+name = "acme"
+url = "https://prompts.example.com/acme.tar.gz"
+sha256 = "abc123"
+
+[[packs]]
+name = "oss"
+url = "https://prompts.example.com/index.json"
+public_key = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+`)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(settings.Packs) != 2 {
+		t.Fatalf("expected 2 packs, got %+v", settings.Packs)
+	}
+	if settings.Packs[0].Name != "acme" || settings.Packs[0].SHA256 != "abc123" {
+		t.Fatalf("unexpected first pack: %+v", settings.Packs[0])
+	}
+	if settings.Packs[1].PublicKey == "" || settings.Packs[1].SignatureURL != "" {
+		t.Fatalf("unexpected second pack: %+v", settings.Packs[1])
+	}
+}
+
+func TestLoadParsesSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.toml")
+
+	content := []byte(`
+[[sources]]
+path = "~/prompts"
+label = "personal"
+
+[[sources]]
+path = "~/work/prompts"
+label = "team"
+priority = 10
+read_only = true
+extensions = [".md"]
+ignore_patterns = ["drafts/"]
+max_file_size_kb = 16
+
+[[sources]]
+path = "~/old"
+enabled = false
+`)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(settings.DefaultDirs) != 0 {
+		t.Fatalf("expected sources to replace the default dir, got %v", settings.DefaultDirs)
+	}
+	if len(settings.Sources) != 3 || !settings.Sources[0].Enabled || settings.Sources[2].Enabled {
+		t.Fatalf("unexpected sources: %+v", settings.Sources)
+	}
+
+	sources := settings.PromptSources()
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 enabled sources, got %+v", sources)
+	}
+	team := sources[0]
+	if team.Label != "team" || !team.ReadOnly || team.MaxFileSizeKB != 16 || len(team.Extensions) != 1 || len(team.IgnorePatterns) != 1 {
+		t.Fatalf("expected the team source first, got %+v", sources)
+	}
+	if sources[1].Label != "personal" {
+		t.Fatalf("expected the personal source second, got %+v", sources[1])
+	}
+}
+
+func TestPromptSourcesKeepsDefaultDirs(t *testing.T) {
+	settings := Settings{
+		DefaultDirs: []string{"a", "b"},
+		Sources:     []SourceSettings{{Path: "c", Label: "team", Priority: 1, Enabled: true}},
+	}
+
+	sources := settings.PromptSources()
+	var paths []string
+	for _, source := range sources {
+		paths = append(paths, source.Path)
+	}
+	if len(paths) != 3 || paths[0] != "c" || paths[1] != "a" || paths[2] != "b" {
+		t.Fatalf("unexpected source order %v", paths)
+	}
+}
+
+func TestLoadReportsMalformedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.toml")
+
+	content := []byte(`
+[fuzzy_search]
+max_results = "many"
+`)
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, err := Load(path)
+	if err == nil || !strings.HasPrefix(err.Error(), path+":3:15: ") {
+		t.Fatalf("expected an error at line 3, column 15, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("default_dir = 3\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "default_dir must be a string or an array of strings") {
+		t.Fatalf("expected a default_dir error, got %v", err)
+	}
+}
+
+func TestDefaultPathHonoursXDGConfigHome(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if got := DefaultPath(); got != filepath.Join(dir, "pmc", "settings.toml") {
+		t.Fatalf("expected the XDG config path, got %s", got)
+	}
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", home)
+	if got := DefaultPath(); got != filepath.Join(home, ".config", "pmc", "settings.toml") {
+		t.Fatalf("expected the home config path, got %s", got)
+	}
+}
@@ -0,0 +1,9 @@
+package config
+
+import _ "embed"
+
+// Starter is a commented settings file with the defaults, written by
+// `pm config init`.
+//
+//go:embed starter.toml
+var Starter []byte
@@ -0,0 +1,242 @@
+// Package exporter writes a prompt library as a portable bundle.
+package exporter
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+// Supported export formats.
+const (
+	FormatJSON     = "json"
+	FormatJSONL    = "jsonl"
+	FormatTar      = "tar"
+	FormatMarkdown = "markdown"
+)
+
+// Formats returns the supported format names.
+func Formats() []string {
+	return []string{FormatJSON, FormatJSONL, FormatTar, FormatMarkdown}
+}
+
+// FormatFor guesses the format from an output file name, reporting false
+// when the extension is not recognised.
+func FormatFor(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".jsonl"):
+		return FormatJSONL, true
+	case strings.HasSuffix(lower, ".json"):
+		return FormatJSON, true
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTar, true
+	case strings.HasSuffix(lower, ".md"), strings.HasSuffix(lower, ".markdown"):
+		return FormatMarkdown, true
+	}
+	return "", false
+}
+
+// Options configure an export.
+type Options struct {
+	// Dirs are the prompt directories the prompts were loaded from. Tarballs
+	// store each prompt file below the base name of its directory.
+	Dirs []string
+	// Title heads the Markdown handbook.
+	Title string
+}
+
+// Write exports prompts to out in the named format. JSON, JSONL and the
+// handbook contain prompts as loaded, with embeds expanded; tarballs contain
+// the original files.
+func Write(out io.Writer, format string, prompts []prompt.Prompt, opts Options) error {
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		if prompts == nil {
+			prompts = []prompt.Prompt{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(prompts)
+	case FormatJSONL:
+		encoder := json.NewEncoder(out)
+		for _, p := range prompts {
+			if err := encoder.Encode(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTar:
+		return writeTar(out, prompts, opts.Dirs)
+	case FormatMarkdown:
+		return writeHandbook(out, prompts, opts.Title)
+	default:
+		return fmt.Errorf("unknown export format %q (expected %s)", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// writeTar stores each prompt file once, keeping its path below the prompt
+// directory it came from. Directories sharing a base name are told apart by
+// a numeric suffix.
+func writeTar(out io.Writer, prompts []prompt.Prompt, dirs []string) error {
+	roots := archiveRoots(dirs)
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	written := make(map[string]bool)
+	for _, p := range prompts {
+		if p.Path == "" || written[p.Path] {
+			continue
+		}
+		written[p.Path] = true
+
+		name, err := archiveName(p.Path, dirs, roots)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: p.ModTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func archiveRoots(dirs []string) []string {
+	roots := make([]string, len(dirs))
+	used := make(map[string]bool)
+	for i, dir := range dirs {
+		base := filepath.Base(filepath.Clean(dir))
+		if base == "." || base == string(filepath.Separator) {
+			base = "prompts"
+		}
+		root := base
+		for n := 2; used[root]; n++ {
+			root = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[root] = true
+		roots[i] = root
+	}
+	return roots
+}
+
+func archiveName(path string, dirs, roots []string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	for i, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(absDir, abs)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return roots[i] + "/" + filepath.ToSlash(rel), nil
+	}
+	return "", fmt.Errorf("%s is outside the prompt directories", path)
+}
+
+// writeHandbook renders prompts as one Markdown document with a table of
+// contents. Prompt bodies are fenced so their own headings stay inside.
+func writeHandbook(out io.Writer, prompts []prompt.Prompt, title string) error {
+	if strings.TrimSpace(title) == "" {
+		title = "Prompt Handbook"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if len(prompts) == 0 {
+		b.WriteString("No prompts.\n")
+		_, err := io.WriteString(out, b.String())
+		return err
+	}
+
+	anchors := make([]string, len(prompts))
+	used := make(map[string]int)
+	b.WriteString("## Contents\n\n")
+	for i, p := range prompts {
+		anchor := anchorFor(p.Name)
+		if n := used[anchor]; n > 0 {
+			used[anchor]++
+			anchor = fmt.Sprintf("%s-%d", anchor, n)
+		} else {
+			used[anchor] = 1
+		}
+		anchors[i] = anchor
+		fmt.Fprintf(&b, "- [%s](#%s)\n", p.Name, anchor)
+	}
+
+	for _, p := range prompts {
+		fmt.Fprintf(&b, "\n## %s\n\n", p.Name)
+		if summary := p.FrontMatterString("summary", "description"); summary != "" {
+			fmt.Fprintf(&b, "%s\n\n", summary)
+		}
+		if len(p.Tags) > 0 {
+			tags := append([]string(nil), p.Tags...)
+			sort.Strings(tags)
+			fmt.Fprintf(&b, "Tags: %s\n\n", "`"+strings.Join(tags, "`, `")+"`")
+		}
+		fence := fenceFor(p.Content)
+		fmt.Fprintf(&b, "%smarkdown\n%s\n%s\n", fence, strings.TrimRight(p.Content, "\r\n"), fence)
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// anchorFor mirrors the heading anchors GitHub generates: lowercase, spaces
+// as dashes, punctuation dropped.
+func anchorFor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// fenceFor returns a backtick fence longer than any run inside content.
+func fenceFor(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
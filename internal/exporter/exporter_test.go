@@ -0,0 +1,149 @@
+package exporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+func testPrompts(t *testing.T) ([]prompt.Prompt, []string) {
+	t.Helper()
+	first, second := filepath.Join(t.TempDir(), "prompts"), filepath.Join(t.TempDir(), "prompts")
+	files := map[string]string{
+		filepath.Join(first, "review.md"):           "---\nsummary: Review code\n---\nReview this.\n",
+		filepath.Join(first, "team", "standup.md"):  "## Yesterday\nWhat happened?\n",
+		filepath.Join(second, "review.md"):          "Shadowed name in another dir\n",
+		filepath.Join(second, "fenced", "code.txt"): "Use ```go fences```.\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirs := []string{first, second}
+	prompts, err := prompt.LoadFromDirs(dirs, prompt.Options{SplitSections: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prompts, dirs
+}
+
+func TestWriteJSONAndJSONL(t *testing.T) {
+	prompts, dirs := testPrompts(t)
+
+	var out bytes.Buffer
+	if err := Write(&out, FormatJSON, prompts, Options{Dirs: dirs}); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+	var decoded []prompt.Prompt
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != len(prompts) {
+		t.Fatalf("expected %d prompts in JSON, got %d (%v)", len(prompts), len(decoded), err)
+	}
+
+	out.Reset()
+	if err := Write(&out, FormatJSONL, prompts, Options{}); err != nil {
+		t.Fatalf("Write(jsonl) error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(prompts) || !strings.HasPrefix(lines[0], `{"name":`) {
+		t.Fatalf("expected one JSON object per line, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := Write(&out, FormatJSON, nil, Options{}); err != nil || strings.TrimSpace(out.String()) != "[]" {
+		t.Fatalf("expected an empty array, got %q (%v)", out.String(), err)
+	}
+}
+
+func TestWriteTarKeepsDirectoryStructure(t *testing.T) {
+	prompts, dirs := testPrompts(t)
+
+	var out bytes.Buffer
+	if err := Write(&out, FormatTar, prompts, Options{Dirs: dirs}); err != nil {
+		t.Fatalf("Write(tar) error = %v", err)
+	}
+
+	gz, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[header.Name] = string(data)
+	}
+
+	want := map[string]string{
+		"prompts/review.md":         "---\nsummary: Review code\n---\nReview this.\n",
+		"prompts/team/standup.md":   "## Yesterday\nWhat happened?\n",
+		"prompts-2/review.md":       "Shadowed name in another dir\n",
+		"prompts-2/fenced/code.txt": "Use ```go fences```.\n",
+	}
+	if len(files) != len(want) {
+		t.Fatalf("expected %d files, got %v", len(want), files)
+	}
+	for name, content := range want {
+		if files[name] != content {
+			t.Errorf("%s = %q, want %q", name, files[name], content)
+		}
+	}
+}
+
+func TestWriteMarkdownHandbook(t *testing.T) {
+	prompts, dirs := testPrompts(t)
+
+	var out bytes.Buffer
+	if err := Write(&out, FormatMarkdown, prompts, Options{Dirs: dirs, Title: "Team Pack"}); err != nil {
+		t.Fatalf("Write(markdown) error = %v", err)
+	}
+	handbook := out.String()
+
+	for _, want := range []string{
+		"# Team Pack\n",
+		"- [review](#review)\n",
+		"- [review](#review-1)\n",
+		"- [standup#Yesterday](#standupyesterday)\n",
+		"## review\n\nReview code\n\n```markdown\nReview this.\n```\n",
+		"````markdown\nUse ```go fences```.\n````\n",
+	} {
+		if !strings.Contains(handbook, want) {
+			t.Errorf("expected %q in handbook:\n%s", want, handbook)
+		}
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	for name, want := range map[string]string{
+		"pack.json": FormatJSON, "pack.JSONL": FormatJSONL, "pack.tar.gz": FormatTar,
+		"pack.tgz": FormatTar, "HANDBOOK.md": FormatMarkdown,
+	} {
+		if got, ok := FormatFor(name); !ok || got != want {
+			t.Errorf("FormatFor(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if _, ok := FormatFor("pack.zip"); ok {
+		t.Error("expected .zip to be unknown")
+	}
+	if err := Write(io.Discard, "zip", nil, Options{}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,256 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+	"github.com/hzionn/prompt-manager-cli/internal/search"
+)
+
+// Options configure the HTTP API.
+type Options struct {
+	// Library is the prompt collection to serve, kept current by its Watch.
+	Library    *prompt.Library
+	SearchOpts search.Options
+}
+
+// Server serves the prompt library as JSON.
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+
+	mu         sync.Mutex
+	prompts    []prompt.Prompt
+	generation uint64
+}
+
+// New returns a Server using opts.
+func New(opts Options) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /prompts", s.handleList)
+	s.mux.HandleFunc("GET /prompts/{name}", s.handleGet)
+	s.mux.HandleFunc("GET /search", s.handleSearch)
+	s.mux.HandleFunc("POST /render", s.handleRender)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// summary is the list representation of a prompt.
+type summary struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Title     string    `json:"title,omitempty"`
+	Summary   string    `json:"summary,omitempty"`
+	Tags      []string  `json:"tags"`
+	Variables []string  `json:"variables"`
+	Modified  time.Time `json:"modified"`
+}
+
+// detail is the full representation of a single prompt.
+type detail struct {
+	prompt.Prompt
+	Variables []string `json:"variables"`
+}
+
+type renderRequest struct {
+	Name      string            `json:"name"`
+	Names     []string          `json:"names"`
+	Variables map[string]string `json:"variables"`
+	Input     string            `json:"input"`
+}
+
+type renderResponse struct {
+	Content string `json:"content"`
+}
+
+// library returns the current prompts in display order, sorting them again
+// only after the library changed.
+func (s *Server) library() []prompt.Prompt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	generation := s.opts.Library.Generation()
+	if s.prompts != nil && s.generation == generation {
+		return s.prompts
+	}
+
+	prompts := s.opts.Library.Prompts()
+	if prompts == nil {
+		prompts = []prompt.Prompt{}
+	}
+	s.prompts = search.Search(prompts, "", search.Options{})
+	s.generation = generation
+	return s.prompts
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	prompts := s.library()
+	if notModified(w, r, etag(prompts)) {
+		return
+	}
+	writeJSON(w, http.StatusOK, summarize(prompts))
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	prompts := s.library()
+	name := r.PathValue("name")
+	p, ok := findPrompt(prompts, name)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("prompt %q not found", name))
+		return
+	}
+	if notModified(w, r, etag([]prompt.Prompt{p})) {
+		return
+	}
+	writeJSON(w, http.StatusOK, detail{Prompt: p, Variables: variables(p)})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	prompts := s.library()
+	query := r.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing query parameter q"))
+		return
+	}
+
+	opts := s.opts.SearchOpts
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", raw))
+			return
+		}
+		opts.MaxResults = limit
+	}
+
+	if notModified(w, r, etag(prompts, query, strconv.Itoa(opts.MaxResults))) {
+		return
+	}
+	writeJSON(w, http.StatusOK, summarize(search.Search(prompts, query, opts)))
+}
+
+func (s *Server) handleRender(w http.ResponseWriter, r *http.Request) {
+	var req renderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+		return
+	}
+
+	names := req.Names
+	if req.Name != "" {
+		names = append([]string{req.Name}, names...)
+	}
+	if len(names) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("render requires name or names"))
+		return
+	}
+
+	prompts := s.library()
+	var parts []string
+	for _, name := range names {
+		p, ok := findPrompt(prompts, name)
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("prompt %q not found", name))
+			return
+		}
+		parts = append(parts, strings.TrimRight(prompt.Render(p.Content, p.Values(req.Variables)), "\r\n"))
+	}
+	if strings.TrimSpace(req.Input) != "" {
+		parts = append(parts, strings.TrimRight(req.Input, "\r\n"))
+	}
+
+	writeJSON(w, http.StatusOK, renderResponse{Content: strings.Join(parts, "\n\n")})
+}
+
+func findPrompt(prompts []prompt.Prompt, name string) (prompt.Prompt, bool) {
+	for _, p := range prompts {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return prompt.Prompt{}, false
+}
+
+func summarize(prompts []prompt.Prompt) []summary {
+	out := make([]summary, 0, len(prompts))
+	for _, p := range prompts {
+		tags := p.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		out = append(out, summary{
+			Name:      p.Name,
+			Path:      p.Path,
+			Title:     p.FrontMatterString("title"),
+			Summary:   p.FrontMatterString("summary"),
+			Tags:      tags,
+			Variables: variables(p),
+			Modified:  p.ModTime,
+		})
+	}
+	return out
+}
+
+func variables(p prompt.Prompt) []string {
+	names := prompt.Variables(p.Content)
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
+
+// etag derives an entity tag from the paths and modification times of the
+// prompts, plus any extra request parameters that shape the response.
+func etag(prompts []prompt.Prompt, extra ...string) string {
+	keys := make([]string, 0, len(prompts))
+	for _, p := range prompts {
+		keys = append(keys, p.Path+"\x00"+p.Name+"\x00"+strconv.FormatInt(p.ModTime.UnixNano(), 10))
+	}
+	sort.Strings(keys)
+
+	hash := fnv.New64a()
+	for _, key := range append(keys, extra...) {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+	}
+	return fmt.Sprintf(`"%x"`, hash.Sum64())
+}
+
+// notModified sets the ETag header and answers conditional requests. It
+// reports true when a 304 response was written.
+func notModified(w http.ResponseWriter, r *http.Request, tag string) bool {
+	w.Header().Set("ETag", tag)
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == tag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
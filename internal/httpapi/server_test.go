@@ -0,0 +1,167 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+	"github.com/hzionn/prompt-manager-cli/internal/search"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *prompt.Library, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "code-review.md"), "---\nsummary: Review checklist\ntags: [review]\n---\nReview this {{language}} change.\n")
+	writeFile(t, filepath.Join(dir, "brainstorm.txt"), "List three bold ideas.\n")
+
+	lib, err := prompt.NewLibrary([]string{dir}, prompt.Options{Extensions: []string{".md", ".txt"}})
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+	server := httptest.NewServer(New(Options{Library: lib, SearchOpts: search.Options{MaxResults: 10}}))
+	t.Cleanup(server.Close)
+	return server, lib, dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func getJSON(t *testing.T, url string, target any) *http.Response {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if target != nil {
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			t.Fatalf("decode %s: %v", url, err)
+		}
+	}
+	return resp
+}
+
+func TestListAndGetPrompts(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	var list []summary
+	resp := getJSON(t, server.URL+"/prompts", &list)
+	if resp.StatusCode != http.StatusOK || len(list) != 2 {
+		t.Fatalf("expected 2 prompts, got %d (%v)", len(list), list)
+	}
+	if list[1].Name != "code-review" || list[1].Summary != "Review checklist" || list[1].Variables[0] != "language" {
+		t.Fatalf("unexpected summary: %+v", list[1])
+	}
+
+	var item detail
+	resp = getJSON(t, server.URL+"/prompts/code-review", &item)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(item.Content, "Review this") {
+		t.Fatalf("unexpected prompt detail: %d %+v", resp.StatusCode, item)
+	}
+
+	resp = getJSON(t, server.URL+"/prompts/missing", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing prompt, got %d", resp.StatusCode)
+	}
+}
+
+func TestSearchEndpoint(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	var results []summary
+	getJSON(t, server.URL+"/search?q=review", &results)
+	if len(results) == 0 || results[0].Name != "code-review" {
+		t.Fatalf("expected code-review first, got %v", results)
+	}
+
+	resp := getJSON(t, server.URL+"/search", nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 without query, got %d", resp.StatusCode)
+	}
+}
+
+func TestRenderMeshesAndSubstitutes(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	body := strings.NewReader(`{"names":["code-review","brainstorm"],"variables":{"language":"Go"},"input":"diff --git"}`)
+	resp, err := http.Post(server.URL+"/render", "application/json", body)
+	if err != nil {
+		t.Fatalf("POST /render: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var rendered renderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rendered); err != nil {
+		t.Fatalf("decode render: %v", err)
+	}
+	want := "Review this Go change.\n\nList three bold ideas.\n\ndiff --git"
+	if rendered.Content != want {
+		t.Fatalf("expected %q, got %q", want, rendered.Content)
+	}
+}
+
+func TestETagChangesWhenFilesChange(t *testing.T) {
+	server, lib, dir := newTestServer(t)
+
+	resp := getJSON(t, server.URL+"/prompts/brainstorm", nil)
+	tag := resp.Header.Get("ETag")
+	if tag == "" {
+		t.Fatal("expected ETag header")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/prompts/brainstorm", nil)
+	req.Header.Set("If-None-Match", tag)
+	cached, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("conditional GET: %v", err)
+	}
+	cached.Body.Close()
+	if cached.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", cached.StatusCode)
+	}
+
+	other := getJSON(t, server.URL+"/prompts/code-review", nil).Header.Get("ETag")
+	list := getJSON(t, server.URL+"/prompts", nil).Header.Get("ETag")
+	restarted := httptest.NewServer(New(Options{Library: lib}))
+	defer restarted.Close()
+	if getJSON(t, restarted.URL+"/prompts/brainstorm", nil).Header.Get("ETag") != tag {
+		t.Fatal("expected the ETag to survive a server restart")
+	}
+
+	path := filepath.Join(dir, "brainstorm.txt")
+	writeFile(t, path, "List five bold ideas.\n")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+	resp = getJSON(t, server.URL+"/prompts/brainstorm", nil)
+	if resp.Header.Get("ETag") != tag {
+		t.Fatal("expected the ETag to hold until the library applies the change")
+	}
+	lib.Refresh(path)
+
+	var item detail
+	resp = getJSON(t, server.URL+"/prompts/brainstorm", &item)
+	if resp.Header.Get("ETag") == tag {
+		t.Fatal("expected ETag to change after the file was modified")
+	}
+	if !strings.Contains(item.Content, "five") {
+		t.Fatalf("expected reloaded content, got %q", item.Content)
+	}
+	if getJSON(t, server.URL+"/prompts/code-review", nil).Header.Get("ETag") != other {
+		t.Fatal("expected other prompts to keep their ETags")
+	}
+	if getJSON(t, server.URL+"/prompts", nil).Header.Get("ETag") == list {
+		t.Fatal("expected the list ETag to change")
+	}
+}
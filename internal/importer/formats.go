@@ -0,0 +1,296 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readPromptLayer reads a PromptLayer CSV export. Columns are found by name;
+// when a version column is present only the latest version of each prompt is
+// kept.
+func readPromptLayer(path string) ([]Entry, []Skipped, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("empty CSV file")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		columns[strings.ReplaceAll(key, " ", "_")] = i
+	}
+	column := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := columns[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	nameCol := column("prompt_name", "name", "title")
+	contentCol := column("prompt_template", "template", "content", "prompt", "text")
+	if nameCol < 0 || contentCol < 0 {
+		return nil, nil, errors.New("CSV needs a prompt name and a template column")
+	}
+	tagsCol := column("tags", "labels")
+	summaryCol := column("description", "commit_message")
+	versionCol := column("version", "version_number")
+
+	field := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var entries []Entry
+	var skipped []Skipped
+	index := make(map[string]int)
+	versions := make(map[string]int)
+	for line, record := range records[1:] {
+		name := field(record, nameCol)
+		if name == "" {
+			skipped = append(skipped, Skipped{Name: fmt.Sprintf("row %d", line+2), Reason: "no prompt name"})
+			continue
+		}
+		content := field(record, contentCol)
+		if content == "" {
+			skipped = append(skipped, Skipped{Name: name, Reason: "empty template"})
+			continue
+		}
+
+		entry := Entry{
+			Name:    name,
+			Summary: field(record, summaryCol),
+			Tags:    splitList(field(record, tagsCol)),
+			Content: content,
+		}
+		version, _ := strconv.Atoi(field(record, versionCol))
+		if i, ok := index[name]; ok && versionCol >= 0 {
+			if version > versions[name] {
+				entries[i], versions[name] = entry, version
+			}
+			continue
+		}
+		index[name], versions[name] = len(entries), version
+		entries = append(entries, entry)
+	}
+	return entries, skipped, nil
+}
+
+// splitList reads tags written as a JSON array or a comma separated list.
+func splitList(value string) []string {
+	var list []string
+	if strings.HasPrefix(value, "[") && json.Unmarshal([]byte(value), &list) == nil {
+		return list
+	}
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' })
+}
+
+// raycastPlaceholder matches Raycast's dynamic placeholders: {selection},
+// {clipboard} and {argument name="..."}.
+var raycastPlaceholder = regexp.MustCompile(`\{(selection|clipboard|argument\s+name="([^"]+)"[^}]*)\}`)
+
+type raycastCommand struct {
+	Title  string `json:"title"`
+	Prompt string `json:"prompt"`
+	Model  string `json:"model"`
+}
+
+// readRaycast reads a Raycast AI commands export, a JSON array of commands.
+// Placeholders become {{variables}}.
+func readRaycast(path string) ([]Entry, []Skipped, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var commands []raycastCommand
+	if err := json.Unmarshal(data, &commands); err != nil {
+		return nil, nil, err
+	}
+
+	var entries []Entry
+	var skipped []Skipped
+	for i, command := range commands {
+		title := strings.TrimSpace(command.Title)
+		if title == "" {
+			skipped = append(skipped, Skipped{Name: fmt.Sprintf("command %d", i+1), Reason: "no title"})
+			continue
+		}
+		if strings.TrimSpace(command.Prompt) == "" {
+			skipped = append(skipped, Skipped{Name: title, Reason: "empty prompt"})
+			continue
+		}
+		content := raycastPlaceholder.ReplaceAllStringFunc(command.Prompt, func(match string) string {
+			groups := raycastPlaceholder.FindStringSubmatch(match)
+			if groups[2] != "" {
+				return "{{" + strings.ReplaceAll(strings.TrimSpace(groups[2]), " ", "_") + "}}"
+			}
+			return "{{" + groups[1] + "}}"
+		})
+		entries = append(entries, Entry{Name: title, Content: content})
+	}
+	return entries, skipped, nil
+}
+
+type espansoMatch struct {
+	Trigger  string   `yaml:"trigger"`
+	Triggers []string `yaml:"triggers"`
+	Regex    string   `yaml:"regex"`
+	Label    string   `yaml:"label"`
+	Replace  string   `yaml:"replace"`
+	Markdown string   `yaml:"markdown"`
+	Form     string   `yaml:"form"`
+}
+
+// espansoField matches [[field]] placeholders in Espanso forms.
+var espansoField = regexp.MustCompile(`\[\[\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\]\]`)
+
+// readEspanso reads an Espanso match file. Each text match becomes a prompt
+// named after its trigger; other triggers become aliases.
+func readEspanso(path string) ([]Entry, []Skipped, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var file struct {
+		Matches []espansoMatch `yaml:"matches"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, nil, err
+	}
+
+	var entries []Entry
+	var skipped []Skipped
+	for i, match := range file.Matches {
+		triggers := match.Triggers
+		if match.Trigger != "" {
+			triggers = append([]string{match.Trigger}, triggers...)
+		}
+		var names []string
+		for _, trigger := range triggers {
+			if name := strings.TrimLeft(strings.TrimSpace(trigger), ":;/\\!@#$%^&*"); name != "" {
+				names = append(names, name)
+			}
+		}
+
+		label := fmt.Sprintf("match %d", i+1)
+		if len(names) > 0 {
+			label = names[0]
+		} else if match.Label != "" {
+			label = match.Label
+		}
+
+		var content string
+		switch {
+		case match.Regex != "":
+			skipped = append(skipped, Skipped{Name: label, Reason: "regex triggers are not supported"})
+			continue
+		case len(names) == 0:
+			skipped = append(skipped, Skipped{Name: label, Reason: "no trigger"})
+			continue
+		case match.Replace != "":
+			content = match.Replace
+		case match.Markdown != "":
+			content = match.Markdown
+		case match.Form != "":
+			content = espansoField.ReplaceAllString(match.Form, "{{$1}}")
+		default:
+			skipped = append(skipped, Skipped{Name: label, Reason: "no text replacement"})
+			continue
+		}
+
+		entries = append(entries, Entry{
+			Name:    names[0],
+			Title:   strings.TrimSpace(match.Label),
+			Aliases: names[1:],
+			Content: content,
+		})
+	}
+	return entries, skipped, nil
+}
+
+type promptyHeader struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Tags        []string `yaml:"tags"`
+}
+
+// readPrompty reads a .prompty file, or every .prompty file in a directory.
+// The body, including its role lines, becomes the prompt content.
+func readPrompty(path string) ([]Entry, []Skipped, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.prompty"))
+		if err != nil {
+			return nil, nil, err
+		}
+		sort.Strings(files)
+	}
+
+	var entries []Entry
+	var skipped []Skipped
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		header, body, err := splitPrompty(data)
+		if err != nil {
+			skipped = append(skipped, Skipped{Name: name, Reason: err.Error()})
+			continue
+		}
+		if strings.TrimSpace(body) == "" {
+			skipped = append(skipped, Skipped{Name: name, Reason: "empty template"})
+			continue
+		}
+		entry := Entry{Name: name, Summary: header.Description, Tags: header.Tags, Content: body}
+		if header.Name != "" && FileName(header.Name) != FileName(name) {
+			entry.Title = header.Name
+		}
+		entries = append(entries, entry)
+	}
+	return entries, skipped, nil
+}
+
+func splitPrompty(data []byte) (promptyHeader, string, error) {
+	var header promptyHeader
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(data, []byte("---\n"))
+	if !ok {
+		return header, string(data), nil
+	}
+	raw, body, ok := bytes.Cut(rest, []byte("\n---\n"))
+	if !ok {
+		return header, "", errors.New("unterminated front matter")
+	}
+	if err := yaml.Unmarshal(raw, &header); err != nil {
+		return header, "", fmt.Errorf("invalid front matter: %w", err)
+	}
+	return header, string(body), nil
+}
@@ -0,0 +1,205 @@
+// Package importer converts prompts exported by other tools into Markdown
+// prompt files.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Entry is one prompt read from another tool's export.
+type Entry struct {
+	Name    string
+	Title   string
+	Summary string
+	Tags    []string
+	Aliases []string
+	Content string
+}
+
+// Skipped is an export entry that could not be converted.
+type Skipped struct {
+	Name   string
+	Reason string
+}
+
+// Collision is an entry that was not written because its name is taken.
+type Collision struct {
+	Name string
+	// Path is the existing file, or empty when the name clashes with a prompt
+	// elsewhere in the library or earlier in the same import.
+	Path string
+}
+
+// Result reports what Write did.
+type Result struct {
+	Written    []string
+	Collisions []Collision
+}
+
+// reader parses an export into entries.
+type reader func(path string) ([]Entry, []Skipped, error)
+
+var readers = map[string]reader{
+	"promptlayer": readPromptLayer,
+	"raycast":     readRaycast,
+	"espanso":     readEspanso,
+	"prompty":     readPrompty,
+}
+
+// Formats returns the supported format names.
+func Formats() []string {
+	names := make([]string, 0, len(readers))
+	for name := range readers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Read parses the export at path in the named format. Entries that cannot be
+// converted are returned as skipped rather than failing the import.
+func Read(format, path string) ([]Entry, []Skipped, error) {
+	read, ok := readers[strings.ToLower(format)]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown import format %q (expected %s)", format, strings.Join(Formats(), ", "))
+	}
+	entries, skipped, err := read(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s export: %w", format, err)
+	}
+	return entries, skipped, nil
+}
+
+// Write stores entries as Markdown files with front matter in dir, named
+// after each entry. Entries whose file already exists, whose name is in
+// existing, or that repeat an earlier entry's name are reported as collisions
+// and left alone.
+func Write(dir string, entries []Entry, existing []string) (Result, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Result{}, err
+	}
+
+	taken := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		taken[strings.ToLower(name)] = true
+	}
+
+	var result Result
+	for _, entry := range entries {
+		name := FileName(entry.Name)
+		key := strings.ToLower(name)
+		path := filepath.Join(dir, name+".md")
+
+		if _, err := os.Lstat(path); err == nil {
+			result.Collisions = append(result.Collisions, Collision{Name: entry.Name, Path: path})
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return result, err
+		}
+		if taken[key] {
+			result.Collisions = append(result.Collisions, Collision{Name: entry.Name})
+			continue
+		}
+
+		data, err := render(entry, name)
+		if err != nil {
+			return result, fmt.Errorf("render %s: %w", entry.Name, err)
+		}
+		// O_EXCL keeps a file created since the check above.
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return result, err
+		}
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return result, err
+		}
+		taken[key] = true
+		result.Written = append(result.Written, path)
+	}
+	return result, nil
+}
+
+type frontMatter struct {
+	Title   string   `yaml:"title,omitempty"`
+	Summary string   `yaml:"summary,omitempty"`
+	Tags    []string `yaml:"tags,omitempty"`
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+func render(entry Entry, fileName string) ([]byte, error) {
+	front := frontMatter{
+		Summary: strings.TrimSpace(entry.Summary),
+		Tags:    cleanList(entry.Tags),
+		Aliases: cleanList(entry.Aliases),
+	}
+	// The file name is the prompt's name, so keep the original as a title
+	// when slugging changed it.
+	if title := strings.TrimSpace(entry.Title); title != "" {
+		front.Title = title
+	} else if strings.TrimSpace(entry.Name) != fileName {
+		front.Title = strings.TrimSpace(entry.Name)
+	}
+
+	var b strings.Builder
+	if front.Title != "" || front.Summary != "" || len(front.Tags) > 0 || len(front.Aliases) > 0 {
+		data, err := yaml.Marshal(front)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("---\n")
+		b.Write(data)
+		b.WriteString("---\n\n")
+	}
+	b.WriteString(strings.TrimSpace(entry.Content))
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+// FileName turns a prompt name into a file name without extension:
+// lowercase words joined by dashes.
+func FileName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "prompt"
+	}
+	return b.String()
+}
+
+func cleanList(values []string) []string {
+	var cleaned []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[strings.ToLower(value)] {
+			continue
+		}
+		seen[strings.ToLower(value)] = true
+		cleaned = append(cleaned, value)
+	}
+	return cleaned
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	return path
+}
+
+func names(entries []Entry) []string {
+	var out []string
+	for _, entry := range entries {
+		out = append(out, entry.Name)
+	}
+	return out
+}
+
+func TestReadPromptLayerKeepsLatestVersion(t *testing.T) {
+	path := writeFile(t, filepath.Join(t.TempDir(), "export.csv"), "Prompt Name,Version,Prompt Template,Tags\n"+
+		"summarize,1,Old summary,\n"+
+		"summarize,2,\"Summarize:\n{{text}}\",\"[\"\"writing\"\", \"\"short\"\"]\"\n"+
+		"empty,1,,\n"+
+		",1,Nameless,\n")
+
+	entries, skipped, err := Read("promptlayer", path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Content != "Summarize:\n{{text}}" {
+		t.Fatalf("expected the latest summarize version, got %+v", entries)
+	}
+	if !reflect.DeepEqual(entries[0].Tags, []string{"writing", "short"}) {
+		t.Errorf("tags = %v", entries[0].Tags)
+	}
+	if len(skipped) != 2 || skipped[0].Reason != "empty template" || skipped[1].Name != "row 5" {
+		t.Errorf("skipped = %+v", skipped)
+	}
+}
+
+func TestReadRaycastConvertsPlaceholders(t *testing.T) {
+	path := writeFile(t, filepath.Join(t.TempDir(), "commands.json"), `[
+		{"title": "Fix Grammar", "prompt": "Fix the grammar of {selection} for {argument name=\"audience\" default=\"everyone\"}."},
+		{"title": "", "prompt": "orphan"},
+		{"title": "Blank", "prompt": " "}
+	]`)
+
+	entries, skipped, err := Read("raycast", path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Content != "Fix the grammar of {{selection}} for {{audience}}." {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if len(skipped) != 2 {
+		t.Errorf("skipped = %+v", skipped)
+	}
+}
+
+func TestReadEspansoUsesTriggers(t *testing.T) {
+	path := writeFile(t, filepath.Join(t.TempDir(), "base.yml"), `matches:
+  - trigger: ":review"
+    label: Code review
+    replace: Review this change.
+  - triggers: [":sum", ":tldr"]
+    form: "Summarize [[text]]"
+  - regex: ":date(?P<n>\\d+)"
+    replace: "{{n}}"
+  - trigger: ":logo"
+    image_path: logo.png
+`)
+
+	entries, skipped, err := Read("espanso", path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got := names(entries); !reflect.DeepEqual(got, []string{"review", "sum"}) {
+		t.Fatalf("names = %v", got)
+	}
+	if entries[0].Title != "Code review" || entries[1].Content != "Summarize {{text}}" || !reflect.DeepEqual(entries[1].Aliases, []string{"tldr"}) {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+	if len(skipped) != 2 || skipped[1].Reason != "no text replacement" {
+		t.Errorf("skipped = %+v", skipped)
+	}
+}
+
+func TestReadPromptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "chat.prompty"), "---\nname: Basic Chat\ndescription: A chat prompt\nmodel:\n  api: chat\n---\nsystem:\nYou are helpful.\n\nuser:\n{{question}}\n")
+	writeFile(t, filepath.Join(dir, "broken.prompty"), "---\nname: [\n---\nbody\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+
+	entries, skipped, err := Read("prompty", dir)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "chat" || entries[0].Title != "Basic Chat" || entries[0].Summary != "A chat prompt" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if !strings.HasPrefix(entries[0].Content, "system:\nYou are helpful.") {
+		t.Errorf("content = %q", entries[0].Content)
+	}
+	if len(skipped) != 1 || skipped[0].Name != "broken" {
+		t.Errorf("skipped = %+v", skipped)
+	}
+}
+
+func TestReadRejectsUnknownFormat(t *testing.T) {
+	if _, _, err := Read("notion", "export.zip"); err == nil || !strings.Contains(err.Error(), "promptlayer") {
+		t.Fatalf("expected unknown format error listing formats, got %v", err)
+	}
+}
+
+func TestWriteReportsCollisions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "review.md"), "Existing\n")
+
+	entries := []Entry{
+		{Name: "Fix Grammar", Summary: "Grammar fixes", Tags: []string{"writing"}, Content: "Fix {{text}}\n"},
+		{Name: "review", Content: "New review"},
+		{Name: "fix grammar!", Content: "Duplicate"},
+		{Name: "brainstorm", Content: "Elsewhere"},
+		{Name: "plain", Content: "Plain prompt"},
+	}
+	result, err := Write(dir, entries, []string{"Brainstorm"})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if len(result.Written) != 2 {
+		t.Fatalf("written = %v", result.Written)
+	}
+	var collided []string
+	for _, collision := range result.Collisions {
+		collided = append(collided, collision.Name)
+	}
+	if !reflect.DeepEqual(collided, []string{"review", "fix grammar!", "brainstorm"}) || result.Collisions[0].Path == "" {
+		t.Errorf("collisions = %+v", result.Collisions)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "fix-grammar.md"))
+	if err != nil {
+		t.Fatalf("read imported prompt: %v", err)
+	}
+	want := "---\ntitle: Fix Grammar\nsummary: Grammar fixes\ntags:\n    - writing\n---\n\nFix {{text}}\n"
+	if string(data) != want {
+		t.Errorf("imported prompt = %q, want %q", data, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "plain.md")); string(data) != "Plain prompt\n" {
+		t.Errorf("expected no front matter for plain prompt, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "review.md")); string(data) != "Existing\n" {
+		t.Errorf("expected existing file untouched, got %q", data)
+	}
+}
@@ -0,0 +1,336 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+	"github.com/hzionn/prompt-manager-cli/internal/search"
+)
+
+// supportedVersions lists the MCP protocol revisions this server speaks,
+// newest first.
+var supportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+const searchToolName = "search_prompts"
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Server answers MCP requests over a line-delimited JSON-RPC stream.
+type Server struct {
+	// Prompts returns the current prompt library.
+	Prompts    func() []prompt.Prompt
+	SearchOpts search.Options
+	Name       string
+	Version    string
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// Serve reads requests from in and writes responses to out until in is
+// exhausted or ctx is cancelled.
+func (s Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		resp, ok := s.handle([]byte(line))
+		if !ok {
+			continue
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle processes one message. It reports false for notifications, which
+// must not be answered.
+func (s Server) handle(data []byte) (response, bool) {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, "parse error: "+err.Error()), true
+	}
+	if len(req.ID) == 0 {
+		return response{}, false
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request"), true
+	}
+
+	result, err := s.dispatch(req)
+	if err != nil {
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) {
+			return errorResponse(req.ID, rpcErr.Code, rpcErr.Message), true
+		}
+		return errorResponse(req.ID, codeInvalidParams, err.Error()), true
+	}
+	return response{JSONRPC: "2.0", ID: req.ID, Result: result}, true
+}
+
+func errorResponse(id json.RawMessage, code int, message string) response {
+	return response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+func (s Server) dispatch(req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "prompts/list":
+		return s.listPrompts(), nil
+	case "prompts/get":
+		return s.getPrompt(req.Params)
+	case "tools/list":
+		return listTools(), nil
+	case "tools/call":
+		return s.callTool(req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
+
+func (s Server) initialize(params json.RawMessage) (any, error) {
+	var args struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+	}
+
+	version := supportedVersions[0]
+	for _, supported := range supportedVersions {
+		if args.ProtocolVersion == supported {
+			version = supported
+		}
+	}
+
+	name := s.Name
+	if name == "" {
+		name = "pm"
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"prompts": map[string]any{"listChanged": false},
+			"tools":   map[string]any{"listChanged": false},
+		},
+		"serverInfo": map[string]any{"name": name, "version": s.Version},
+	}, nil
+}
+
+type promptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+type promptInfo struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []promptArgument `json:"arguments,omitempty"`
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type promptMessage struct {
+	Role    string      `json:"role"`
+	Content textContent `json:"content"`
+}
+
+func (s Server) prompts() []prompt.Prompt {
+	if s.Prompts == nil {
+		return nil
+	}
+	return search.Search(s.Prompts(), "", search.Options{})
+}
+
+func (s Server) listPrompts() any {
+	prompts := s.prompts()
+	infos := make([]promptInfo, 0, len(prompts))
+	seen := make(map[string]struct{})
+	for _, p := range prompts {
+		if _, ok := seen[p.Name]; ok {
+			continue
+		}
+		seen[p.Name] = struct{}{}
+		infos = append(infos, describe(p))
+	}
+	return map[string]any{"prompts": infos}
+}
+
+func describe(p prompt.Prompt) promptInfo {
+	info := promptInfo{
+		Name:        p.Name,
+		Title:       p.FrontMatterString("title"),
+		Description: description(p),
+	}
+	declared := make(map[string]struct{})
+	for _, input := range p.Inputs {
+		declared[input.Name] = struct{}{}
+		info.Arguments = append(info.Arguments, promptArgument{
+			Name:        input.Name,
+			Description: input.Description,
+			Required:    input.Required,
+		})
+	}
+	for _, name := range prompt.Variables(p.Content) {
+		if _, ok := declared[name]; ok {
+			continue
+		}
+		info.Arguments = append(info.Arguments, promptArgument{Name: name, Required: true})
+	}
+	return info
+}
+
+// description prefers the summary front matter and falls back to the
+// description key used by structured prompt files.
+func description(p prompt.Prompt) string {
+	return p.FrontMatterString("summary", "description")
+}
+
+func (s Server) getPrompt(params json.RawMessage) (any, error) {
+	var args struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+
+	for _, p := range s.prompts() {
+		if p.Name != args.Name {
+			continue
+		}
+		values := p.Values(args.Arguments)
+		var messages []promptMessage
+		for _, message := range p.ChatMessages() {
+			// MCP prompts carry only user and assistant messages, so system
+			// instructions are sent as user messages.
+			role := message.Role
+			if role != prompt.RoleAssistant {
+				role = prompt.RoleUser
+			}
+			messages = append(messages, promptMessage{
+				Role:    role,
+				Content: textContent{Type: "text", Text: prompt.Render(message.Content, values)},
+			})
+		}
+		return map[string]any{
+			"description": description(p),
+			"messages":    messages,
+		}, nil
+	}
+	return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("prompt %q not found", args.Name)}
+}
+
+func listTools() any {
+	return map[string]any{
+		"tools": []map[string]any{{
+			"name":        searchToolName,
+			"description": "Fuzzy search the prompt library by name, aliases, tags, front matter and content.",
+			"inputSchema": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{"type": "string", "description": "Search query"},
+					"limit": map[string]any{"type": "integer", "description": "Maximum number of results"},
+				},
+				"required": []string{"query"},
+			},
+		}},
+	}
+}
+
+func (s Server) callTool(params json.RawMessage) (any, error) {
+	var args struct {
+		Name      string `json:"name"`
+		Arguments struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit"`
+		} `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+	if args.Name != searchToolName {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", args.Name)}
+	}
+
+	opts := s.SearchOpts
+	if args.Arguments.Limit > 0 {
+		opts.MaxResults = args.Arguments.Limit
+	}
+
+	var lines []string
+	matches := []promptInfo{}
+	var prompts []prompt.Prompt
+	if s.Prompts != nil {
+		prompts = s.Prompts()
+	}
+	for _, p := range search.Search(prompts, args.Arguments.Query, opts) {
+		info := describe(p)
+		matches = append(matches, info)
+		line := p.Name
+		if info.Description != "" {
+			line += " - " + info.Description
+		}
+		lines = append(lines, line)
+	}
+
+	text := strings.Join(lines, "\n")
+	if len(lines) == 0 {
+		text = fmt.Sprintf("No prompts match %q.", args.Arguments.Query)
+	}
+	return map[string]any{
+		"content":           []textContent{{Type: "text", Text: text}},
+		"structuredContent": map[string]any{"prompts": matches},
+	}, nil
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+func testServer() Server {
+	prompts := []prompt.Prompt{
+		{
+			Name:        "code-review",
+			Content:     "Review this {{language}} change for {{audience}}.",
+			FrontMatter: map[string]any{"summary": "Checklist for reviews\n"},
+		},
+		{Name: "brainstorm", Content: "List three bold ideas."},
+	}
+	return Server{Prompts: func() []prompt.Prompt { return prompts }, Version: "test"}
+}
+
+func exchange(t *testing.T, server Server, messages ...string) []map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(messages, "\n") + "\n")
+	if err := server.Serve(context.Background(), in, &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	var responses []map[string]any
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var resp map[string]any
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServeInitializeAndSkipNotifications(t *testing.T) {
+	responses := exchange(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	)
+
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d: %v", len(responses), responses)
+	}
+	result := responses[0]["result"].(map[string]any)
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Fatalf("expected negotiated protocol version, got %v", result["protocolVersion"])
+	}
+	if _, ok := result["capabilities"].(map[string]any)["prompts"]; !ok {
+		t.Fatalf("expected prompts capability, got %v", result["capabilities"])
+	}
+}
+
+func TestServeListsPromptsWithArguments(t *testing.T) {
+	responses := exchange(t, testServer(), `{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`)
+
+	prompts := responses[0]["result"].(map[string]any)["prompts"].([]any)
+	if len(prompts) != 2 {
+		t.Fatalf("expected 2 prompts, got %v", prompts)
+	}
+	review := prompts[1].(map[string]any)
+	if review["name"] != "code-review" || review["description"] != "Checklist for reviews" {
+		t.Fatalf("unexpected prompt info: %v", review)
+	}
+	args := review["arguments"].([]any)
+	if len(args) != 2 || args[0].(map[string]any)["name"] != "language" {
+		t.Fatalf("expected template variables as arguments, got %v", args)
+	}
+}
+
+func TestServeGetPromptRendersArguments(t *testing.T) {
+	responses := exchange(t, testServer(),
+		`{"jsonrpc":"2.0","id":"a","method":"prompts/get","params":{"name":"code-review","arguments":{"language":"Go","audience":"reviewers"}}}`,
+		`{"jsonrpc":"2.0","id":"b","method":"prompts/get","params":{"name":"missing"}}`,
+	)
+
+	messages := responses[0]["result"].(map[string]any)["messages"].([]any)
+	content := messages[0].(map[string]any)["content"].(map[string]any)
+	if content["text"] != "Review this Go change for reviewers." {
+		t.Fatalf("expected rendered prompt, got %v", content["text"])
+	}
+	if responses[1]["error"] == nil {
+		t.Fatalf("expected error for unknown prompt, got %v", responses[1])
+	}
+}
+
+func TestServeSearchTool(t *testing.T) {
+	responses := exchange(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_prompts","arguments":{"query":"review"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+	)
+
+	tools := responses[0]["result"].(map[string]any)["tools"].([]any)
+	if tools[0].(map[string]any)["name"] != searchToolName {
+		t.Fatalf("expected search tool, got %v", tools)
+	}
+	content := responses[1]["result"].(map[string]any)["content"].([]any)
+	text := content[0].(map[string]any)["text"].(string)
+	if !strings.HasPrefix(text, "code-review - Checklist for reviews") {
+		t.Fatalf("expected search results text, got %q", text)
+	}
+	errObj := responses[2]["error"].(map[string]any)
+	if errObj["code"].(float64) != codeMethodNotFound {
+		t.Fatalf("expected method not found, got %v", errObj)
+	}
+}
+
+func TestServeGetStructuredPromptKeepsMessages(t *testing.T) {
+	prompts := []prompt.Prompt{{
+		Name:        "translate",
+		Content:     "system:\nTranslate into {{language}}.\n\nuser:\n{{text}}\n",
+		FrontMatter: map[string]any{"description": "Translate text"},
+		Messages: []prompt.Message{
+			{Role: prompt.RoleSystem, Content: "Translate into {{language}}."},
+			{Role: prompt.RoleAssistant, Content: "Ready."},
+			{Role: prompt.RoleUser, Content: "{{text}}"},
+		},
+		Inputs: []prompt.Input{{Name: "language", Description: "Target language", Default: "French"}},
+	}}
+	server := Server{Prompts: func() []prompt.Prompt { return prompts }}
+
+	responses := exchange(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"translate","arguments":{"text":"Hello"}}}`,
+	)
+
+	info := responses[0]["result"].(map[string]any)["prompts"].([]any)[0].(map[string]any)
+	args := info["arguments"].([]any)
+	language := args[0].(map[string]any)
+	if info["description"] != "Translate text" || language["description"] != "Target language" || language["required"] != false {
+		t.Fatalf("unexpected prompt info: %v", info)
+	}
+	if len(args) != 2 || args[1].(map[string]any)["name"] != "text" {
+		t.Fatalf("expected declared and template arguments, got %v", args)
+	}
+
+	result := responses[1]["result"].(map[string]any)
+	var got []string
+	for _, message := range result["messages"].([]any) {
+		message := message.(map[string]any)
+		got = append(got, message["role"].(string)+": "+message["content"].(map[string]any)["text"].(string))
+	}
+	want := "user: Translate into French.|assistant: Ready.|user: Hello"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, "|"))
+	}
+}
@@ -0,0 +1,144 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Discovery restricts which files are treated as prompts, for vaults where
+// prompts live among ordinary notes. A file qualifies when it matches any of
+// the configured criteria; with none configured every file qualifies.
+type Discovery struct {
+	// Paths are gitignore-style patterns relative to each prompt directory.
+	// Files inside a matching path qualify without being inspected.
+	Paths []string
+	// Markers are front matter key/value pairs, such as type: prompt, that
+	// mark a note as a prompt. List values match when they contain the value.
+	Markers map[string]string
+	// Tags qualify notes carrying any of these tags or their subtags, in front
+	// matter or inline.
+	Tags []string
+}
+
+// Enabled reports whether any criterion is configured.
+func (d Discovery) Enabled() bool {
+	return len(d.Paths) > 0 || len(d.Markers) > 0 || len(d.Tags) > 0
+}
+
+// inPaths reports whether path lies inside one of the configured paths,
+// relative to root.
+func (d Discovery) inPaths(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for _, pattern := range d.Paths {
+		rule, ok := parseIgnoreRule(pattern)
+		if !ok || rule.negate {
+			continue
+		}
+		for i := 1; i <= len(segments); i++ {
+			if rule.matches(segments[:i], i < len(segments)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// admits inspects file contents for a marker or tag. Files without front
+// matter are rejected without parsing unless inline tags can qualify them.
+func (d Discovery) admits(data []byte) bool {
+	if !hasFrontMatter(data) && len(d.Tags) == 0 {
+		return false
+	}
+
+	front, content := parseFrontMatter(data)
+	for key, want := range d.Markers {
+		if frontMatterHas(front, key, want) {
+			return true
+		}
+	}
+	if len(d.Tags) == 0 {
+		return false
+	}
+
+	candidate := Prompt{Tags: extractTags(front)}
+	if !d.hasTag(candidate) {
+		candidate.Tags = extractInlineTags(content)
+	}
+	return d.hasTag(candidate)
+}
+
+// admitsStructured checks a parsed structured prompt, whose markers and tags
+// are top-level keys of the document rather than Markdown front matter.
+func (d Discovery) admitsStructured(p Prompt) bool {
+	for key, want := range d.Markers {
+		if frontMatterHas(p.FrontMatter, key, want) {
+			return true
+		}
+	}
+	return d.hasTag(p)
+}
+
+func (d Discovery) hasTag(p Prompt) bool {
+	for _, tag := range d.Tags {
+		if p.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func frontMatterHas(front map[string]any, key, want string) bool {
+	value, ok := front[key]
+	if !ok || value == nil {
+		return false
+	}
+	if list, ok := value.([]any); ok {
+		for _, item := range list {
+			if strings.EqualFold(fmt.Sprint(item), want) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.EqualFold(fmt.Sprint(value), want)
+}
+
+// loadCandidate reads the file at path, which was found below root, and
+// parses it into prompts. It returns none when opts.Discovery excludes the file.
+func loadCandidate(root, path string, info os.FileInfo, opts Options) ([]Prompt, error) {
+	opts = opts.forRoot(root)
+	qualified := !opts.Discovery.Enabled() || opts.Discovery.inPaths(root, path)
+	if !qualified && len(opts.Discovery.Markers) == 0 && len(opts.Discovery.Tags) == 0 {
+		return nil, nil
+	}
+
+	data, err := readPromptFile(path)
+	if err != nil {
+		return nil, err
+	}
+	structured := isStructuredPrompt(path)
+	if !qualified && !structured && !opts.Discovery.admits(data) {
+		return nil, nil
+	}
+
+	prompts, err := buildPrompts(path, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !qualified && structured && (len(prompts) == 0 || !opts.Discovery.admitsStructured(prompts[0])) {
+		return nil, nil
+	}
+	source := opts.Sources[root]
+	for i := range prompts {
+		prompts[i].ModTime = info.ModTime()
+		prompts[i].Source = source.Label
+		prompts[i].ReadOnly = source.ReadOnly
+	}
+	return prompts, nil
+}
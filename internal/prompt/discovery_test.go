@@ -0,0 +1,104 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAppliesDiscoveryFilter(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "Prompts", "team"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Prompts/team/review.md": "Review this change.\n",
+		"journal.md":             "Went for a walk.\n",
+		"marked.md":              "---\ntype: Prompt\n---\nSummarise the meeting.\n",
+		"typed-list.md":          "---\ntype: [note, prompt]\n---\nList form.\n",
+		"tagged.md":              "---\ntags: [prompt/writing]\n---\nRewrite this.\n",
+		"inline.md":              "Translate this. #prompt\n",
+		"other-type.md":          "---\ntype: meeting\ntags: [work]\n---\nNotes.\n",
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+	}
+
+	load := func(discovery Discovery) string {
+		t.Helper()
+		prompts, diagnostics, err := LoadContext(context.Background(), []string{dir}, Options{Discovery: discovery})
+		if err != nil || len(diagnostics) > 0 {
+			t.Fatalf("LoadContext() = %v, %v", diagnostics, err)
+		}
+		var names []string
+		for _, p := range prompts {
+			names = append(names, p.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := load(Discovery{}); strings.Count(got, ",") != len(files)-1 {
+		t.Fatalf("expected every file without a filter, got %s", got)
+	}
+	if got := load(Discovery{Paths: []string{"Prompts/"}}); got != "review" {
+		t.Fatalf("expected only files under Prompts/, got %s", got)
+	}
+	if got := load(Discovery{Markers: map[string]string{"type": "prompt"}}); got != "marked,typed-list" {
+		t.Fatalf("expected marked notes, got %s", got)
+	}
+	if got := load(Discovery{Paths: []string{"/Prompts"}, Tags: []string{"prompt"}}); got != "review,inline,tagged" {
+		t.Fatalf("expected path and tagged notes, got %s", got)
+	}
+}
+
+func TestLoadAppliesDiscoveryToStructuredPrompts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"marked.prompt.yaml": "type: prompt\nmessages:\n  - role: user\n    content: Summarise.\n",
+		"tagged.prompt.json": `{"tags": ["prompt/writing"], "messages": [{"role": "user", "content": "Rewrite."}]}`,
+		"plain.prompt.yaml":  "description: helper\nmessages:\n  - role: user\n    content: Plain.\n",
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(dir, name), content)
+	}
+
+	load := func(discovery Discovery) string {
+		t.Helper()
+		prompts, diagnostics, err := LoadContext(context.Background(), []string{dir}, Options{Discovery: discovery})
+		if err != nil || len(diagnostics) > 0 {
+			t.Fatalf("LoadContext() = %v, %v", diagnostics, err)
+		}
+		var names []string
+		for _, p := range prompts {
+			names = append(names, p.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := load(Discovery{Markers: map[string]string{"type": "prompt"}}); got != "marked" {
+		t.Fatalf("expected the marked structured prompt, got %s", got)
+	}
+	if got := load(Discovery{Tags: []string{"prompt"}}); got != "tagged" {
+		t.Fatalf("expected the tagged structured prompt, got %s", got)
+	}
+}
+
+func TestLibraryDropsPromptsThatLoseTheirMarker(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "note.md")
+	writeTestFile(t, path, "---\ntype: prompt\n---\nDo the thing.\n")
+
+	lib, err := NewLibrary([]string{dir}, Options{Discovery: Discovery{Markers: map[string]string{"type": "prompt"}}})
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+	assertLibraryNames(t, lib, "note")
+
+	writeTestFile(t, path, "Just a note now.\n")
+	if changes := lib.Refresh(path); len(changes) != 1 || changes[0].Op != Removed {
+		t.Fatalf("expected the note to be removed, got %v", changes)
+	}
+	assertLibraryNames(t, lib)
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// fileID identifies a directory by its fully resolved path where inode
+// numbers are not available.
+type fileID string
+
+func fileIdentity(path string, _ os.FileInfo) (fileID, bool) {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	return fileID(absPath(real)), true
+}
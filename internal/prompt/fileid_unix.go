@@ -0,0 +1,22 @@
+//go:build unix
+
+package prompt
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID identifies a directory by device and inode.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func fileIdentity(_ string, info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
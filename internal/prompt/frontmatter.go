@@ -0,0 +1,149 @@
+package prompt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterFormat is a front matter block opened and closed by a delimiter
+// line.
+type frontMatterFormat struct {
+	delimiter string
+	unmarshal func([]byte, any) error
+}
+
+// frontMatterFormats are the delimited styles: YAML between "---" lines, TOML
+// between "+++" lines as in Hugo, and JSON between ";;;" lines. A bare JSON
+// object is never front matter, so prompts may open with a JSON example.
+var frontMatterFormats = []frontMatterFormat{
+	{delimiter: "---", unmarshal: yaml.Unmarshal},
+	{delimiter: "+++", unmarshal: toml.Unmarshal},
+	{delimiter: ";;;", unmarshal: unmarshalJSONFrontMatter},
+}
+
+// hasFrontMatter reports whether data opens with a front matter delimiter,
+// without parsing the block.
+func hasFrontMatter(data []byte) bool {
+	opening := openingLine(data)
+	for _, format := range frontMatterFormats {
+		if opening == format.delimiter {
+			return true
+		}
+	}
+	return false
+}
+
+func openingLine(data []byte) string {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	return strings.TrimSpace(string(line))
+}
+
+// parseFrontMatter splits data into its front matter and the remaining
+// content. Blocks that cannot be parsed are treated as part of the content.
+func parseFrontMatter(data []byte) (map[string]any, string) {
+	opening := openingLine(data)
+	for _, format := range frontMatterFormats {
+		if opening == format.delimiter {
+			return parseDelimitedFrontMatter(data, format)
+		}
+	}
+	return nil, string(data)
+}
+
+func parseDelimitedFrontMatter(data []byte, format frontMatterFormat) (map[string]any, string) {
+	reader := bufio.NewReader(bytes.NewReader(data))
+	if _, err := reader.ReadString('\n'); err != nil {
+		return nil, string(data)
+	}
+
+	var buf strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if strings.TrimSpace(line) == format.delimiter {
+			break
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, string(data)
+		}
+
+		buf.WriteString(line)
+	}
+
+	rest, _ := io.ReadAll(reader)
+	content := strings.TrimLeft(string(rest), "\r\n")
+
+	raw := buf.String()
+	if strings.TrimSpace(raw) == "" {
+		return nil, content
+	}
+
+	var front map[string]any
+	if err := format.unmarshal([]byte(raw), &front); err != nil {
+		// If parsing fails, fall back to treating the data as raw content.
+		return nil, string(data)
+	}
+	return normalizeFrontMatter(front), content
+}
+
+// unmarshalJSONFrontMatter decodes the body of a ";;;" block, which may omit
+// the enclosing braces.
+func unmarshalJSONFrontMatter(data []byte, v any) error {
+	if trimmed := bytes.TrimSpace(data); !bytes.HasPrefix(trimmed, []byte("{")) {
+		data = append(append([]byte("{"), trimmed...), '}')
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// normalizeFrontMatter trims keys and converts values to the types YAML
+// produces, so front matter reads the same whatever its format.
+func normalizeFrontMatter(input map[string]any) map[string]any {
+	if input == nil {
+		return nil
+	}
+
+	normalized := make(map[string]any, len(input))
+	for key, value := range input {
+		normalized[strings.TrimSpace(key)] = normalizeFrontMatterValue(value)
+	}
+	return normalized
+}
+
+func normalizeFrontMatterValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return normalizeFrontMatter(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = normalizeFrontMatterValue(item)
+		}
+		return items
+	case int64:
+		return int(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case toml.LocalDate:
+		return v.String()
+	case toml.LocalTime:
+		return v.String()
+	case toml.LocalDateTime:
+		return v.String()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,73 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFrontMatterFormats(t *testing.T) {
+	want := map[string]any{
+		"title":    "Release notes",
+		"tags":     []any{"writing", "release"},
+		"priority": 2,
+		"metadata": map[string]any{"owner": "docs"},
+	}
+
+	tests := map[string]string{
+		"yaml":                "---\ntitle: Release notes\ntags: [writing, release]\npriority: 2\nmetadata:\n  owner: docs\n---\n\nWrite the notes.\n",
+		"toml":                "+++\ntitle = \"Release notes\"\ntags = [\"writing\", \"release\"]\npriority = 2\n[metadata]\nowner = \"docs\"\n+++\nWrite the notes.\n",
+		"json":                ";;;\n{\"title\": \"Release notes\", \"tags\": [\"writing\", \"release\"], \"priority\": 2, \"metadata\": {\"owner\": \"docs\"}}\n;;;\nWrite the notes.\n",
+		"json without braces": ";;;\n\"title\": \"Release notes\",\n\"tags\": [\"writing\", \"release\"],\n\"priority\": 2,\n\"metadata\": {\"owner\": \"docs\"}\n;;;\nWrite the notes.\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			front, content := parseFrontMatter([]byte(data))
+			if !reflect.DeepEqual(front, want) {
+				t.Errorf("front matter = %#v, want %#v", front, want)
+			}
+			if content != "Write the notes.\n" {
+				t.Errorf("content = %q", content)
+			}
+			p, err := buildPrompt("notes.md", []byte(data))
+			if err != nil {
+				t.Fatalf("buildPrompt() error = %v", err)
+			}
+			if !reflect.DeepEqual(p.Tags, []string{"writing", "release"}) {
+				t.Errorf("tags = %v", p.Tags)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatterKeepsUnparsedBlocksAsContent(t *testing.T) {
+	tests := map[string]string{
+		"json document":   "{\n  \"role\": \"user\"\n}\n",
+		"json example":    "{\n  \"role\": \"user\"\n}\n\nReply in the shape above.\n",
+		"invalid toml":    "+++\ntitle = \n+++\nBody\n",
+		"unclosed block":  ";;;\n{\"title\": \"x\"}\nBody\n",
+		"inline object":   "{\"role\": \"user\"} is the shape to return.\n",
+		"no front matter": "Plain prompt\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			front, content := parseFrontMatter([]byte(data))
+			if front != nil || content != data {
+				t.Errorf("expected %q to stay content, got %#v and %q", data, front, content)
+			}
+		})
+	}
+}
+
+func TestBuildPromptKeepsLeadingJSONExample(t *testing.T) {
+	data := "{\n  \"tags\": [\"ignored\"]\n}\n\nReturn an object like the one above.\n"
+	p, err := buildPrompt("shape.md", []byte(data))
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if p.FrontMatter != nil || len(p.Tags) != 0 || !strings.HasPrefix(p.Content, "{\n  \"tags\"") {
+		t.Fatalf("expected the JSON example in the content, got %#v, %v and %q", p.FrontMatter, p.Tags, p.Content)
+	}
+}
@@ -0,0 +1,197 @@
+package prompt
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PMIgnoreFile is the per-directory ignore file honoured during discovery.
+const PMIgnoreFile = ".pmignore"
+
+// ignoreRule is a single gitignore-style pattern.
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// parseIgnoreRule parses one line of an ignore file following gitignore
+// syntax. It reports false for blank lines and comments.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, "\r")
+	trimmed := strings.TrimRight(line, " ")
+	if strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+		trimmed += " "
+	}
+	line = trimmed
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	switch {
+	case strings.HasPrefix(line, "!"):
+		rule.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to its directory;
+	// otherwise it matches a name at any depth.
+	anchored := strings.Contains(line, "/")
+	rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	if !anchored {
+		rule.segments = append([]string{"**"}, rule.segments...)
+	}
+	return rule, true
+}
+
+// matches reports whether the rule applies to rel, a slash-separated path
+// relative to the directory that defined the rule.
+func (r ignoreRule) matches(rel []string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	return matchSegments(r.segments, rel)
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				// A trailing "/**" matches everything inside, not the directory itself.
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignorer applies gitignore semantics below a prompt directory: the built-in
+// defaults followed by the configured ignore_patterns act as a root-level
+// ignore file, and ignore files found in each directory add rules for their
+// subtree. Later rules win, so a deeper file can re-include what a shallower
+// one excluded.
+type ignorer struct {
+	root   string
+	global []ignoreRule
+	files  []string
+	rules  map[string][]ignoreRule
+}
+
+func newIgnorer(root string, opts Options) *ignorer {
+	ig := &ignorer{
+		root:  filepath.Clean(root),
+		files: []string{PMIgnoreFile},
+		rules: make(map[string][]ignoreRule),
+	}
+	if opts.UseGitignore {
+		ig.files = []string{".gitignore", PMIgnoreFile}
+	}
+	for _, pattern := range append(append([]string(nil), defaultIgnorePatterns...), opts.IgnorePatterns...) {
+		if rule, ok := parseIgnoreRule(pattern); ok {
+			ig.global = append(ig.global, rule)
+		}
+	}
+	return ig
+}
+
+// Ignored reports whether path, a file or directory below the root, is
+// excluded. Ignore files themselves are never treated as prompts.
+func (ig *ignorer) Ignored(filePath string, isDir bool) bool {
+	rel, err := filepath.Rel(ig.root, filePath)
+	if err != nil {
+		rel, err = filepath.Rel(absPath(ig.root), absPath(filePath))
+	}
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if !isDir && ig.isIgnoreFile(filePath) {
+		return true
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	ignored := false
+	for _, rule := range ig.global {
+		if rule.matches(segments, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	dir := ig.root
+	for depth := range segments {
+		if depth > 0 {
+			dir = filepath.Join(dir, segments[depth-1])
+		}
+		for _, rule := range ig.dirRules(dir) {
+			if rule.matches(segments[depth:], isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// isIgnoreFile reports whether path names one of the honoured ignore files.
+func (ig *ignorer) isIgnoreFile(filePath string) bool {
+	base := filepath.Base(filePath)
+	for _, name := range ig.files {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (ig *ignorer) dirRules(dir string) []ignoreRule {
+	if rules, ok := ig.rules[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	for _, name := range ig.files {
+		rules = append(rules, readIgnoreFile(filepath.Join(dir, name))...)
+	}
+	ig.rules[dir] = rules
+	return rules
+}
+
+func readIgnoreFile(filePath string) []ignoreRule {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
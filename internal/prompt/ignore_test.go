@@ -0,0 +1,119 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{".DS_Store", ".DS_Store", false, true},
+		{".DS_Store", "team/.DS_Store", false, true},
+		{"*.tmp", "a/b/c.tmp", false, true},
+		{"drafts/", "drafts", true, true},
+		{"drafts/", "drafts", false, false},
+		{"/notes.md", "notes.md", false, true},
+		{"/notes.md", "team/notes.md", false, false},
+		{"team/notes.md", "team/notes.md", false, true},
+		{"team/notes.md", "x/team/notes.md", false, false},
+		{"**/drafts/**", "a/drafts/b.md", false, true},
+		{"**/drafts/**", "drafts/b.md", false, true},
+		{"**/drafts/**", "drafts", true, false},
+		{"a/**/b.md", "a/b.md", false, true},
+		{"a/**/b.md", "a/x/y/b.md", false, true},
+		{"archive/**", "archive/2023/q1.md", false, true},
+		{`\#hash.md`, "#hash.md", false, true},
+		{"wip.md   ", "wip.md", false, true},
+	}
+
+	for _, tt := range tests {
+		rule, ok := parseIgnoreRule(tt.pattern)
+		if !ok {
+			t.Fatalf("parseIgnoreRule(%q) rejected the pattern", tt.pattern)
+		}
+		if got := rule.matches(strings.Split(tt.path, "/"), tt.isDir); got != tt.want {
+			t.Errorf("%q matching %q (dir=%v) = %v, want %v", tt.pattern, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "   ", "# comment", "/"} {
+		if _, ok := parseIgnoreRule(line); ok {
+			t.Errorf("expected %q to be skipped", line)
+		}
+	}
+}
+
+func TestLoadHonoursIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"drafts", "team/archive", "vendor"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{
+		"keep.md", "drafts/wip.md", "drafts/ready.md",
+		"team/a.md", "team/b.md", "team/archive/old.md", "vendor/lib.md",
+	} {
+		writeTestFile(t, filepath.Join(dir, name), name+"\n")
+	}
+	writeTestFile(t, filepath.Join(dir, PMIgnoreFile), "drafts/**\n!drafts/ready.md\n")
+	writeTestFile(t, filepath.Join(dir, "team", PMIgnoreFile), "# team rules\narchive/\nb.md\n")
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), "vendor/\n")
+
+	load := func(opts Options) []string {
+		t.Helper()
+		prompts, diagnostics, err := LoadContext(context.Background(), []string{dir}, opts)
+		if err != nil || len(diagnostics) > 0 {
+			t.Fatalf("LoadContext() = %v, %v", diagnostics, err)
+		}
+		var names []string
+		for _, p := range prompts {
+			rel, _ := filepath.Rel(dir, p.Path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return names
+	}
+
+	got := load(Options{Extensions: []string{".md"}})
+	want := "drafts/ready.md,keep.md,team/a.md,vendor/lib.md"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+
+	got = load(Options{Extensions: []string{".md"}, UseGitignore: true, IgnorePatterns: []string{"keep.md"}})
+	want = "drafts/ready.md,team/a.md"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("expected %s with .gitignore, got %v", want, got)
+	}
+}
+
+func TestLibraryRefreshAppliesEditedIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "alpha.md"), "Alpha\n")
+	writeTestFile(t, filepath.Join(dir, "beta.md"), "Beta\n")
+
+	lib, err := NewLibrary([]string{dir}, Options{Extensions: []string{".md"}})
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+	assertLibraryNames(t, lib, "alpha", "beta")
+
+	ignoreFile := filepath.Join(dir, PMIgnoreFile)
+	writeTestFile(t, ignoreFile, "beta.md\n")
+	lib.Refresh(ignoreFile)
+	assertLibraryNames(t, lib, "alpha")
+
+	if err := os.Remove(ignoreFile); err != nil {
+		t.Fatal(err)
+	}
+	lib.Refresh(ignoreFile)
+	assertLibraryNames(t, lib, "alpha", "beta")
+}
@@ -0,0 +1,593 @@
+package prompt
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Op identifies the kind of change applied to a library entry.
+type Op int
+
+const (
+	// Created reports a prompt that was added to the library.
+	Created Op = iota + 1
+	// Modified reports a prompt whose file changed.
+	Modified
+	// Removed reports a prompt that left the library.
+	Removed
+)
+
+func (op Op) String() string {
+	switch op {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change describes a single library update.
+type Change struct {
+	Op   Op
+	Path string
+}
+
+// WatchOptions tune Library.Watch.
+type WatchOptions struct {
+	// Debounce is how long the watcher waits for the filesystem to settle
+	// before applying a burst of events. Defaults to 100ms.
+	Debounce time.Duration
+	// PollInterval is the scan interval of the polling fallback. Defaults to 1s.
+	PollInterval time.Duration
+	// Poll forces the polling backend even where native events are available.
+	Poll bool
+}
+
+// Library is an in-memory prompt collection that Watch keeps in sync with
+// the filesystem.
+type Library struct {
+	dirs []string
+	opts Options
+
+	mu         sync.RWMutex
+	entries    map[string]libraryEntry // keyed by absolute path
+	problems   map[string]Diagnostic   // keyed by absolute path
+	owners     map[string]string       // real path -> key of the entry loading it
+	generation uint64
+}
+
+// libraryEntry holds the prompts parsed from one file.
+type libraryEntry struct {
+	path    string
+	real    string
+	prompts []Prompt
+	root    int
+}
+
+// NewLibrary loads the prompts under dirs into a new Library.
+func NewLibrary(dirs []string, opts Options) (*Library, error) {
+	lib := &Library{
+		dirs:     append([]string(nil), dirs...),
+		opts:     opts,
+		entries:  make(map[string]libraryEntry),
+		problems: make(map[string]Diagnostic),
+		owners:   make(map[string]string),
+	}
+	for i, dir := range lib.dirs {
+		prompts, diagnostics, err := load(context.Background(), []string{dir}, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range diagnostics {
+			lib.problems[absPath(d.Path)] = d
+		}
+		loaded := make(map[string]bool)
+		for _, p := range prompts {
+			key, real := absPath(p.Path), lib.realPath(p.Path)
+			entry, ok := lib.entries[key]
+			if ok && !loaded[key] {
+				continue // already loaded from an earlier directory
+			}
+			if owner, linked := lib.owners[real]; linked && owner != key {
+				continue // reached through a symlink from an earlier directory
+			}
+			loaded[key] = true
+			entry.path, entry.real, entry.root = p.Path, real, i
+			entry.prompts = append(entry.prompts, p)
+			lib.entries[key] = entry
+			lib.owners[real] = key
+		}
+	}
+	return lib, nil
+}
+
+// Prompts returns a snapshot of the library in directory order, matching the
+// order produced by LoadFromDirs.
+func (l *Library) Prompts() []Prompt {
+	l.mu.RLock()
+	entries := make([]libraryEntry, 0, len(l.entries))
+	for _, entry := range l.entries {
+		entries = append(entries, entry)
+	}
+	l.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].root != entries[j].root {
+			return entries[i].root < entries[j].root
+		}
+		return walkOrderLess(entries[i].path, entries[j].path)
+	})
+
+	var prompts []Prompt
+	for _, entry := range entries {
+		prompts = append(prompts, entry.prompts...)
+	}
+	return resolveLinks(prompts)
+}
+
+// Diagnostics reports the files that currently fail to load, sorted by path.
+func (l *Library) Diagnostics() []Diagnostic {
+	l.mu.RLock()
+	diagnostics := make([]Diagnostic, 0, len(l.problems))
+	for _, d := range l.problems {
+		diagnostics = append(diagnostics, d)
+	}
+	l.mu.RUnlock()
+
+	sort.Slice(diagnostics, func(i, j int) bool { return diagnostics[i].Path < diagnostics[j].Path })
+	return diagnostics
+}
+
+// Generation counts the batches of changes applied to the library. It starts
+// at zero and changes whenever Prompts may return something different.
+func (l *Library) Generation() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.generation
+}
+
+// walkOrderLess orders paths the way filepath.WalkDir visits them, comparing
+// one path element at a time.
+func walkOrderLess(a, b string) bool {
+	as := strings.Split(filepath.ToSlash(a), "/")
+	bs := strings.Split(filepath.ToSlash(b), "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// Watch follows filesystem changes under the library directories until ctx is
+// done. Each batch of applied changes is delivered on the returned channel,
+// which is closed when watching stops. Native notifications are used where
+// available, with a polling fallback elsewhere.
+func (l *Library) Watch(ctx context.Context, opts WatchOptions) (<-chan []Change, error) {
+	if opts.Debounce <= 0 {
+		opts.Debounce = 100 * time.Millisecond
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	var backend watchBackend
+	if !opts.Poll {
+		native, err := newNativeBackend(l.dirs, l.watchedDirs())
+		if err == nil {
+			backend = native
+		}
+	}
+	if backend == nil {
+		backend = newPollBackend(l.dirs, l.opts, opts.PollInterval)
+	}
+
+	changes := make(chan []Change)
+	go l.run(ctx, backend, opts.Debounce, changes)
+	return changes, nil
+}
+
+func (l *Library) run(ctx context.Context, backend watchBackend, debounce time.Duration, changes chan<- []Change) {
+	defer close(changes)
+	defer backend.Close()
+
+	pending := make(map[string]struct{})
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case path, ok := <-backend.Events():
+			if !ok {
+				return
+			}
+			pending[path] = struct{}{}
+			timer.Reset(debounce)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			pending = make(map[string]struct{})
+
+			applied := l.Refresh(paths...)
+			if len(applied) == 0 {
+				continue
+			}
+			select {
+			case changes <- applied:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Refresh re-reads the given files or directories and updates the library,
+// returning the changes that were applied. Paths that no longer exist are
+// removed along with anything below them.
+func (l *Library) Refresh(paths ...string) []Change {
+	var applied []Change
+	sort.Strings(paths)
+	for _, path := range paths {
+		applied = append(applied, l.refresh(path)...)
+	}
+	if len(applied) > 0 {
+		l.mu.Lock()
+		l.generation++
+		l.mu.Unlock()
+	}
+	return applied
+}
+
+func (l *Library) refresh(path string) []Change {
+	root, ok := l.rootFor(path)
+	if !ok {
+		return l.removeUnder(path)
+	}
+	ig := newIgnorer(l.dirs[root], l.opts.forRoot(l.dirs[root]))
+	if ig.isIgnoreFile(path) {
+		// Edited ignore rules can hide or reveal anything in their directory.
+		return l.refresh(filepath.Dir(path))
+	}
+
+	info, err := os.Lstat(path)
+	if err == nil && l.opts.FollowSymlinks && info.Mode()&fs.ModeSymlink != 0 {
+		if target, statErr := os.Stat(path); statErr == nil {
+			info = target
+		}
+	}
+	if err != nil || l.ignored(ig, path, info.IsDir()) {
+		return l.removeUnder(path)
+	}
+
+	if info.IsDir() {
+		var changes []Change
+		visited := make(map[string]struct{})
+		newTreeWalker(l.opts.FollowSymlinks).Walk(path, func(sub string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if sub != path && ig.Ignored(sub, true) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if ig.Ignored(sub, false) {
+				return nil
+			}
+			visited[absPath(sub)] = struct{}{}
+			changes = append(changes, l.refreshFile(root, sub)...)
+			return nil
+		})
+		// Entries the walk no longer reaches were deleted, moved away as part
+		// of a directory, or newly ignored.
+		removed := l.removeMatching(path, func(key string) bool {
+			_, ok := visited[key]
+			return !ok
+		})
+		return append(removed, changes...)
+	}
+
+	return l.refreshFile(root, path)
+}
+
+func (l *Library) refreshFile(root int, path string) []Change {
+	key := absPath(path)
+	info, err := os.Lstat(path)
+	if err == nil && l.opts.FollowSymlinks && info.Mode()&fs.ModeSymlink != 0 {
+		if target, statErr := os.Stat(path); statErr == nil {
+			info = target
+		}
+	}
+	if err != nil || !acceptFile(path, info, l.opts.forRoot(l.dirs[root])) {
+		l.setProblem(key, nil)
+		return l.remove(key)
+	}
+
+	l.mu.RLock()
+	existing, exists := l.entries[key]
+	l.mu.RUnlock()
+	if exists && existing.prompts[0].ModTime.Equal(info.ModTime()) {
+		return nil
+	}
+
+	prompts, err := loadCandidate(l.dirs[root], path, info, l.opts)
+	if err != nil {
+		l.setProblem(key, &Diagnostic{Path: path, Err: err})
+		return l.remove(key)
+	}
+	if len(prompts) == 0 {
+		l.setProblem(key, nil)
+		return l.remove(key)
+	}
+
+	real := l.realPath(path)
+	var changes []Change
+	l.mu.Lock()
+	if owner, linked := l.owners[real]; linked && owner != key {
+		// The file is also reached through a symlink; the copy first in walk
+		// order wins, as when loading.
+		other := l.entries[owner]
+		if other.root < root || (other.root == root && walkOrderLess(other.path, path)) {
+			l.mu.Unlock()
+			l.setProblem(key, nil)
+			return l.remove(key)
+		}
+		l.dropEntry(owner, other)
+		changes = append(changes, Change{Op: Removed, Path: other.path})
+	}
+	l.entries[key] = libraryEntry{path: path, real: real, prompts: prompts, root: root}
+	l.owners[real] = key
+	delete(l.problems, key)
+	l.mu.Unlock()
+
+	if exists {
+		return append(changes, Change{Op: Modified, Path: path})
+	}
+	return append(changes, Change{Op: Created, Path: path})
+}
+
+// realPath is the key under which the file at path is loaded only once: its
+// resolved path when following symlinks, so that a file linked into several
+// directories shows up once as with LoadFromDirs.
+func (l *Library) realPath(path string) string {
+	key := absPath(path)
+	if l.opts.FollowSymlinks {
+		if real, err := filepath.EvalSymlinks(key); err == nil {
+			return real
+		}
+	}
+	return key
+}
+
+// dropEntry deletes the entry at key. The caller holds l.mu.
+func (l *Library) dropEntry(key string, entry libraryEntry) {
+	delete(l.entries, key)
+	if l.owners[entry.real] == key {
+		delete(l.owners, entry.real)
+	}
+}
+
+// setProblem records or, when d is nil, clears the diagnostic for key.
+func (l *Library) setProblem(key string, d *Diagnostic) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if d == nil {
+		delete(l.problems, key)
+		return
+	}
+	l.problems[key] = *d
+}
+
+func (l *Library) remove(key string) []Change {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[key]
+	if !ok {
+		return nil
+	}
+	l.dropEntry(key, entry)
+	return []Change{{Op: Removed, Path: entry.path}}
+}
+
+// removeUnder drops path and every entry below it.
+func (l *Library) removeUnder(path string) []Change {
+	return l.removeMatching(path, func(string) bool { return true })
+}
+
+func (l *Library) removeMatching(path string, match func(key string) bool) []Change {
+	prefix := absPath(path)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key := range l.problems {
+		if isWithin(key, prefix) && match(key) {
+			delete(l.problems, key)
+		}
+	}
+
+	var changes []Change
+	for key, entry := range l.entries {
+		if !isWithin(key, prefix) {
+			continue
+		}
+		if !match(key) {
+			continue
+		}
+		l.dropEntry(key, entry)
+		changes = append(changes, Change{Op: Removed, Path: entry.path})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// rootFor returns the index of the first library directory containing path.
+func (l *Library) rootFor(path string) (int, bool) {
+	abs := absPath(path)
+	for i, dir := range l.dirs {
+		if isWithin(abs, absPath(dir)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ignored reports whether path or any directory between it and its library
+// root is excluded, mirroring the pruning done while walking.
+func (l *Library) ignored(ig *ignorer, path string, isDir bool) bool {
+	rel, err := filepath.Rel(ig.root, path)
+	if err != nil {
+		rel, err = filepath.Rel(absPath(ig.root), absPath(path))
+	}
+	if err != nil || rel == "." {
+		return false
+	}
+	current := ig.root
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i, part := range parts {
+		current = filepath.Join(current, part)
+		if ig.Ignored(current, isDir || i < len(parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchedDirs lists every directory the native backend must subscribe to.
+func (l *Library) watchedDirs() []string {
+	var dirs []string
+	walker := newTreeWalker(l.opts.FollowSymlinks)
+	for _, root := range l.dirs {
+		ig := newIgnorer(root, l.opts.forRoot(root))
+		walker.Walk(root, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil || !d.IsDir() {
+				return nil
+			}
+			if ig.Ignored(path, true) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		})
+	}
+	return dirs
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// watchBackend delivers paths that may have changed.
+type watchBackend interface {
+	Events() <-chan string
+	Close() error
+}
+
+// pollBackend detects changes by periodically scanning the directories.
+type pollBackend struct {
+	events chan string
+	done   chan struct{}
+	once   sync.Once
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func newPollBackend(dirs []string, opts Options, interval time.Duration) *pollBackend {
+	p := &pollBackend{events: make(chan string), done: make(chan struct{})}
+	// Take the baseline scan before returning so that changes made right
+	// after Watch starts are not folded into it.
+	go p.loop(scanStamps(dirs, opts), dirs, opts, interval)
+	return p
+}
+
+func (p *pollBackend) Events() <-chan string { return p.events }
+
+func (p *pollBackend) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+func (p *pollBackend) loop(previous map[string]fileStamp, dirs []string, opts Options, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := scanStamps(dirs, opts)
+		var changed []string
+		for path, stamp := range current {
+			if old, ok := previous[path]; !ok || old != stamp {
+				changed = append(changed, path)
+			}
+		}
+		for path := range previous {
+			if _, ok := current[path]; !ok {
+				changed = append(changed, path)
+			}
+		}
+		previous = current
+
+		for _, path := range changed {
+			select {
+			case p.events <- path:
+			case <-p.done:
+				return
+			}
+		}
+	}
+}
+
+func scanStamps(dirs []string, opts Options) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	walker := newTreeWalker(opts.FollowSymlinks)
+	for _, dir := range dirs {
+		ig := newIgnorer(dir, opts.forRoot(dir))
+		walker.Walk(dir, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if ig.Ignored(path, true) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// Ignore files are scanned too so that editing them is noticed.
+			if ig.Ignored(path, false) && !ig.isIgnoreFile(path) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+	return stamps
+}
@@ -0,0 +1,217 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLibraryWatchAppliesChanges(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		name := "native"
+		if poll {
+			name = "poll"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, filepath.Join(dir, "alpha.md"), "Alpha prompt\n")
+
+			opts := Options{
+				Extensions:     []string{".md"},
+				IgnorePatterns: []string{"drafts"},
+				MaxFileSize:    1024,
+			}
+			lib, err := NewLibrary([]string{dir}, opts)
+			if err != nil {
+				t.Fatalf("NewLibrary() error = %v", err)
+			}
+			assertLibraryNames(t, lib, "alpha")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			changes, err := lib.Watch(ctx, WatchOptions{Debounce: 20 * time.Millisecond, PollInterval: 20 * time.Millisecond, Poll: poll})
+			if err != nil {
+				t.Fatalf("Watch() error = %v", err)
+			}
+
+			writeTestFile(t, filepath.Join(dir, "beta.md"), "Beta prompt\n")
+			waitForLibrary(t, lib, changes, "alpha", "beta")
+
+			writeTestFile(t, filepath.Join(dir, "notes.txt"), "not a prompt\n")
+			writeTestFile(t, filepath.Join(dir, "large.md"), string(make([]byte, 2048)))
+			if err := os.MkdirAll(filepath.Join(dir, "drafts"), 0o755); err != nil {
+				t.Fatalf("MkdirAll() error = %v", err)
+			}
+			writeTestFile(t, filepath.Join(dir, "drafts", "draft.md"), "Draft\n")
+			if err := os.MkdirAll(filepath.Join(dir, "team"), 0o755); err != nil {
+				t.Fatalf("MkdirAll() error = %v", err)
+			}
+			writeTestFile(t, filepath.Join(dir, "team", "gamma.md"), "Gamma prompt\n")
+			waitForLibrary(t, lib, changes, "alpha", "beta", "gamma")
+
+			if err := os.Rename(filepath.Join(dir, "beta.md"), filepath.Join(dir, "delta.md")); err != nil {
+				t.Fatalf("Rename() error = %v", err)
+			}
+			waitForLibrary(t, lib, changes, "alpha", "delta", "gamma")
+
+			if err := os.Remove(filepath.Join(dir, "alpha.md")); err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+			waitForLibrary(t, lib, changes, "delta", "gamma")
+
+			writeTestFile(t, filepath.Join(dir, "delta.md"), "Delta prompt, revised\n")
+			future := time.Now().Add(time.Minute)
+			os.Chtimes(filepath.Join(dir, "delta.md"), future, future)
+			deadline := time.After(5 * time.Second)
+			for lib.Prompts()[0].Content != "Delta prompt, revised\n" {
+				select {
+				case <-changes:
+				case <-deadline:
+					t.Fatalf("timed out waiting for modification, got %q", lib.Prompts()[0].Content)
+				}
+			}
+
+			cancel()
+			for range changes {
+			}
+		})
+	}
+}
+
+func TestLibraryTracksDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.md")
+	writeTestFile(t, path, "\xff\xfe")
+
+	lib, err := NewLibrary([]string{dir}, Options{})
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+	if diagnostics := lib.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Path != path {
+		t.Fatalf("expected a diagnostic for %s, got %v", path, diagnostics)
+	}
+
+	writeTestFile(t, path, "Fixed\n")
+	if lib.Generation() != 0 {
+		t.Fatalf("expected a new library at generation 0, got %d", lib.Generation())
+	}
+	if changes := lib.Refresh(path); len(changes) != 1 || changes[0].Op != Created {
+		t.Fatalf("expected the fixed prompt to be created, got %v", changes)
+	}
+	if lib.Generation() != 1 {
+		t.Fatalf("expected the refresh to advance the generation, got %d", lib.Generation())
+	}
+	if diagnostics := lib.Diagnostics(); len(diagnostics) != 0 {
+		t.Fatalf("expected diagnostics to clear, got %v", diagnostics)
+	}
+}
+
+func TestLibraryLoadsSymlinkedDirectoryOnce(t *testing.T) {
+	base := t.TempDir()
+	shared := filepath.Join(base, "shared")
+	if err := os.MkdirAll(shared, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	writeTestFile(t, filepath.Join(shared, "alpha.md"), "Alpha prompt\n")
+	first, second := filepath.Join(base, "first"), filepath.Join(base, "second")
+	for _, dir := range []string{first, second} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.Symlink(shared, filepath.Join(dir, "shared")); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	opts := Options{Extensions: []string{".md"}, MaxFileSize: 1024, FollowSymlinks: true}
+	lib, err := NewLibrary([]string{first, second}, opts)
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+	assertLibraryNames(t, lib, "alpha")
+	if got := lib.Prompts()[0].Path; got != filepath.Join(first, "shared", "alpha.md") {
+		t.Fatalf("expected the first directory's copy, got %s", got)
+	}
+
+	lib.Refresh(second, first)
+	assertLibraryNames(t, lib, "alpha")
+
+	if err := os.Remove(filepath.Join(first, "shared")); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	lib.Refresh(first, second)
+	assertLibraryNames(t, lib, "alpha")
+	if got := lib.Prompts()[0].Path; got != filepath.Join(second, "shared", "alpha.md") {
+		t.Fatalf("expected the second directory's copy, got %s", got)
+	}
+}
+
+func TestWalkOrderLessMatchesWalkDir(t *testing.T) {
+	if !walkOrderLess("root/a/b.md", "root/a.md") {
+		t.Fatal("expected directory a to sort before a.md like filepath.WalkDir")
+	}
+	if walkOrderLess("root/b.md", "root/a/z.md") {
+		t.Fatal("expected a/z.md before b.md")
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func libraryNames(lib *Library) []string {
+	var names []string
+	for _, p := range lib.Prompts() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func assertLibraryNames(t *testing.T, lib *Library, want ...string) {
+	t.Helper()
+	got := libraryNames(lib)
+	if len(got) != len(want) {
+		t.Fatalf("expected prompts %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected prompts %v, got %v", want, got)
+		}
+	}
+}
+
+func waitForLibrary(t *testing.T, lib *Library, changes <-chan []Change, want ...string) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		got := libraryNames(lib)
+		if equalNames(got, want) {
+			return
+		}
+		select {
+		case _, ok := <-changes:
+			if !ok {
+				t.Fatalf("watch stopped while waiting for %v (have %v)", want, got)
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for %v, have %v", want, got)
+		}
+	}
+}
+
+func equalNames(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
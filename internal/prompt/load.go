@@ -0,0 +1,216 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Diagnostic records a file or directory that could not be loaded. Such
+// problems are reported alongside the prompts instead of aborting the load.
+type Diagnostic struct {
+	Path string
+	Err  error
+}
+
+func (d Diagnostic) Error() string {
+	err := d.Err
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && pathErr.Path == d.Path {
+		err = pathErr.Err
+	}
+	return d.Path + ": " + err.Error()
+}
+
+func (d Diagnostic) Unwrap() error { return d.Err }
+
+// loadJob is a file accepted by the directory walk, numbered in walk order.
+type loadJob struct {
+	index int
+	root  string
+	path  string
+	info  os.FileInfo
+}
+
+type loadResult struct {
+	index   int
+	path    string
+	prompts []Prompt
+	err     error
+}
+
+// LoadContext discovers prompt files under dirs like LoadFromDirs, reading and
+// parsing them on a bounded pool of opts.Workers goroutines. The prompts are in
+// directory walk order regardless of how the reads interleave. Files that fail
+// to load are skipped and described by the returned diagnostics, sorted by
+// path; the error is only set when ctx is cancelled. Wikilinks and embeds
+// are resolved across all of dirs.
+func LoadContext(ctx context.Context, dirs []string, opts Options) ([]Prompt, []Diagnostic, error) {
+	prompts, diagnostics, err := load(ctx, dirs, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resolveLinks(prompts), diagnostics, nil
+}
+
+// load is LoadContext without link resolution, for callers that keep the
+// parsed prompts and resolve links on their own snapshots.
+func load(ctx context.Context, dirs []string, opts Options) ([]Prompt, []Diagnostic, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan loadJob, workers)
+	results := make(chan loadResult, workers)
+	walkDiagnostics := make(chan []Diagnostic, 1)
+
+	go func() {
+		defer close(jobs)
+		walkDiagnostics <- walkDirs(ctx, dirs, opts, jobs)
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				prompts, err := loadCandidate(job.root, job.path, job.info, opts)
+				results <- loadResult{index: job.index, path: job.path, prompts: prompts, err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var loaded []loadResult
+	for result := range results {
+		for len(loaded) <= result.index {
+			loaded = append(loaded, loadResult{})
+		}
+		loaded[result.index] = result
+	}
+	diagnostics := <-walkDiagnostics
+
+	// Workers skip outstanding jobs once ctx is done, so a cancelled load
+	// has gaps and must not be returned.
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	prompts := make([]Prompt, 0, len(loaded))
+	for _, result := range loaded {
+		if result.err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Path: result.path, Err: result.err})
+			continue
+		}
+		prompts = append(prompts, result.prompts...)
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool { return diagnostics[i].Path < diagnostics[j].Path })
+	return prompts, diagnostics, nil
+}
+
+// walkDirs sends every candidate prompt file under dirs to jobs, skipping
+// duplicates reachable from more than one directory (by real path when
+// following symlinks). Entries that cannot be
+// read are returned as diagnostics; missing directories are ignored.
+func walkDirs(ctx context.Context, dirs []string, opts Options, jobs chan<- loadJob) []Diagnostic {
+	seen := make(map[string]struct{})
+	index := 0
+	var diagnostics []Diagnostic
+	walker := newTreeWalker(opts.FollowSymlinks)
+
+	for _, dir := range dirs {
+		opts := opts.forRoot(dir)
+		ig := newIgnorer(dir, opts)
+		walker.Walk(dir, func(path string, d os.DirEntry, walkErr error) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if walkErr != nil {
+				if path == dir && errors.Is(walkErr, fs.ErrNotExist) {
+					return nil
+				}
+				diagnostics = append(diagnostics, Diagnostic{Path: path, Err: walkErr})
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if d.IsDir() {
+				if ig.Ignored(path, true) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if ig.Ignored(path, false) {
+				return nil
+			}
+
+			if !acceptExtension(path, opts.Extensions) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					diagnostics = append(diagnostics, Diagnostic{Path: path, Err: err})
+				}
+				return nil
+			}
+			if !acceptFile(path, info, opts) || isDirLink(path, info) {
+				return nil
+			}
+
+			key, err := filepath.Abs(path)
+			if err == nil && opts.FollowSymlinks {
+				key, err = filepath.EvalSymlinks(key)
+			}
+			if err != nil {
+				diagnostics = append(diagnostics, Diagnostic{Path: path, Err: err})
+				return nil
+			}
+
+			if _, ok := seen[key]; ok {
+				return nil
+			}
+			seen[key] = struct{}{}
+
+			select {
+			case jobs <- loadJob{index: index, root: dir, path: path, info: info}:
+				index++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+
+	return diagnostics
+}
+
+// isDirLink reports whether info describes a symlink to a directory, which is
+// left alone unless symlinks are followed.
+func isDirLink(path string, info os.FileInfo) bool {
+	if info.Mode()&fs.ModeSymlink == 0 {
+		return false
+	}
+	target, err := os.Stat(path)
+	return err == nil && target.IsDir()
+}
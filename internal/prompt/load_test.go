@@ -0,0 +1,203 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadContextKeepsWalkOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpus(t, dir, 200)
+
+	opts := Options{Extensions: []string{".md"}, Workers: 1}
+	want, _, err := LoadContext(context.Background(), []string{dir}, opts)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if len(want) != 200 {
+		t.Fatalf("expected 200 prompts, got %d", len(want))
+	}
+
+	opts.Workers = 16
+	for range 5 {
+		got, _, err := LoadContext(context.Background(), []string{dir, dir}, opts)
+		if err != nil {
+			t.Fatalf("LoadContext() error = %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d prompts, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i].Path != want[i].Path || got[i].Content != want[i].Content {
+				t.Fatalf("prompt %d: expected %s, got %s", i, want[i].Path, got[i].Path)
+			}
+		}
+	}
+}
+
+func TestLoadContextCancelled(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpus(t, dir, 50)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := LoadContext(ctx, []string{dir}, Options{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLoadContextReportsDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	writeCorpus(t, dir, 20)
+	if err := os.Symlink(filepath.Join(dir, "loop.md"), filepath.Join(dir, "loop.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	writeTestFile(t, filepath.Join(dir, "binary.md"), "\xff\xfe\x00garbage")
+
+	prompts, diagnostics, err := LoadContext(context.Background(), []string{dir, filepath.Join(dir, "missing")}, Options{Workers: 4})
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if len(prompts) != 20 {
+		t.Fatalf("expected the 20 readable prompts, got %d", len(prompts))
+	}
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diagnostics)
+	}
+	if diagnostics[0].Path != filepath.Join(dir, "binary.md") || !errors.Is(diagnostics[0], ErrInvalidEncoding) {
+		t.Errorf("expected encoding diagnostic for binary.md, got %v", diagnostics[0])
+	}
+	if diagnostics[1].Path != filepath.Join(dir, "loop.md") {
+		t.Errorf("expected diagnostic for loop.md, got %v", diagnostics[1])
+	}
+	if strings.Count(diagnostics[1].Error(), "loop.md") != 1 {
+		t.Errorf("expected the path to be reported once, got %q", diagnostics[1].Error())
+	}
+}
+
+func TestLoadContextFollowsSymlinks(t *testing.T) {
+	base := t.TempDir()
+	vault := filepath.Join(base, "vault")
+	shared := filepath.Join(base, "shared")
+	for _, dir := range []string{vault, shared} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(vault, "own.md"), "Own\n")
+	writeTestFile(t, filepath.Join(shared, "team.md"), "Team\n")
+	for link, target := range map[string]string{"team": shared, "team-again": shared, "loop": vault} {
+		if err := os.Symlink(target, filepath.Join(vault, link)); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	prompts, diagnostics, err := LoadContext(context.Background(), []string{vault}, Options{})
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if len(prompts) != 1 || len(diagnostics) != 0 {
+		t.Fatalf("expected linked directories to be ignored by default, got %v and %v", prompts, diagnostics)
+	}
+
+	opts := Options{Extensions: []string{".md"}, FollowSymlinks: true}
+	prompts, diagnostics, err = LoadContext(context.Background(), []string{vault, shared}, opts)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	var paths []string
+	for _, p := range prompts {
+		paths = append(paths, p.Path)
+	}
+	want := []string{filepath.Join(vault, "own.md"), filepath.Join(vault, "team", "team.md")}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Path != filepath.Join(vault, "loop") || !errors.Is(diagnostics[0], ErrSymlinkLoop) {
+		t.Fatalf("expected a loop diagnostic for vault/loop, got %v", diagnostics)
+	}
+}
+
+func BenchmarkLoadContext(b *testing.B) {
+	dir := b.TempDir()
+	writeCorpus(b, dir, 10000)
+	opts := Options{Extensions: []string{".md"}}
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			opts.Workers = workers
+			for b.Loop() {
+				prompts, _, err := LoadContext(context.Background(), []string{dir}, opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(prompts) != 10000 {
+					b.Fatalf("expected 10000 prompts, got %d", len(prompts))
+				}
+			}
+		})
+	}
+}
+
+// writeCorpus generates n Markdown prompts with front matter, spread over
+// nested directories like a real vault.
+func writeCorpus(tb testing.TB, dir string, n int) {
+	tb.Helper()
+	for i := range n {
+		sub := filepath.Join(dir, fmt.Sprintf("area-%02d", i%20), fmt.Sprintf("topic-%02d", i%7))
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		content := fmt.Sprintf("---\ntitle: Prompt %d\nsummary: Generated prompt number %d\ntags: [bench, area-%02d]\n---\nWrite about topic %d.\n", i, i, i%20, i)
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("prompt-%05d.md", i)), []byte(content), 0o600); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestLoadContextAppliesSourceOptions(t *testing.T) {
+	personal := t.TempDir()
+	team := t.TempDir()
+	for _, dir := range []string{personal, team} {
+		if err := os.Mkdir(filepath.Join(dir, "drafts"), 0o755); err != nil {
+			t.Fatalf("Mkdir() error = %v", err)
+		}
+	}
+	writeTestFile(t, filepath.Join(personal, "notes.txt"), "personal notes")
+	writeTestFile(t, filepath.Join(personal, "drafts", "idea.md"), "personal draft")
+	writeTestFile(t, filepath.Join(team, "notes.txt"), "team notes")
+	writeTestFile(t, filepath.Join(team, "review.md"), "team review")
+	writeTestFile(t, filepath.Join(team, "drafts", "wip.md"), "team draft")
+	writeTestFile(t, filepath.Join(team, "huge.md"), strings.Repeat("x", 2048))
+
+	opts := Options{
+		Extensions: []string{".md", ".txt"},
+		Sources: map[string]Source{
+			team: {
+				Label:          "team",
+				ReadOnly:       true,
+				Extensions:     []string{".md"},
+				IgnorePatterns: []string{"drafts/"},
+				MaxFileSize:    1024,
+			},
+		},
+	}
+	prompts, _, err := LoadContext(context.Background(), []string{personal, team}, opts)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+
+	var got []string
+	for _, p := range prompts {
+		got = append(got, fmt.Sprintf("%s/%s/%t", p.Source, p.Name, p.ReadOnly))
+	}
+	want := "/idea/false /notes/false team/review/true"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
@@ -0,0 +1,241 @@
+package prompt
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// defaultIgnorePatterns skip Obsidian's vault metadata and trash. They are
+// applied before ignore_patterns, so a "!" pattern can re-include them.
+var defaultIgnorePatterns = []string{".obsidian/", ".trash/"}
+
+var (
+	// inlineTagPattern matches Obsidian #tags, which need at least one
+	// non-digit character and may be nested with "/".
+	inlineTagPattern = regexp.MustCompile(`(?:^|[\s(])#([\p{L}\p{N}_\-/]*[\p{L}_\-/][\p{L}\p{N}_\-/]*)`)
+	// wikilinkPattern matches [[target]], [[target#heading]], [[target|label]]
+	// and their ![[embed]] forms.
+	wikilinkPattern = regexp.MustCompile(`(!?)\[\[([^\[\]|#]*)(?:#([^\[\]|]*))?(?:\|[^\[\]]*)?\]\]`)
+	inlineCode      = regexp.MustCompile("`[^`\n]*`")
+)
+
+// HasTag reports whether the prompt carries tag or one of its nested subtags,
+// so "project" matches "project/alpha". The comparison ignores case and a
+// leading "#".
+func (p Prompt) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "#"), "/"))
+	if tag == "" {
+		return false
+	}
+	for _, candidate := range p.Tags {
+		candidate = strings.ToLower(candidate)
+		if candidate == tag || strings.HasPrefix(candidate, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// proseLines returns the lines of content outside fenced code blocks, with
+// inline code spans blanked out, so tags and links in code are not picked up.
+func proseLines(content string) []string {
+	var lines []string
+	fence := ""
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		lines = append(lines, inlineCode.ReplaceAllString(line, ""))
+	}
+	return lines
+}
+
+// extractInlineTags collects #tags written in the body.
+func extractInlineTags(content string) []string {
+	var tags []string
+	for _, line := range proseLines(content) {
+		for _, match := range inlineTagPattern.FindAllStringSubmatch(line, -1) {
+			if tag := strings.Trim(match[1], "/"); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// extractLinks collects the targets of [[wikilinks]] in the body, excluding
+// embeds, in the order they appear.
+func extractLinks(content string) []string {
+	var links []string
+	for _, line := range proseLines(content) {
+		for _, match := range wikilinkPattern.FindAllStringSubmatch(line, -1) {
+			target := strings.TrimSpace(match[2])
+			if match[1] == "!" || target == "" {
+				continue
+			}
+			links = append(links, target)
+		}
+	}
+	return cleanSlice(links)
+}
+
+// promptIndex looks prompts up the way Obsidian resolves link targets: by
+// file name, alias, or a trailing path such as "team/review".
+type promptIndex struct {
+	prompts []Prompt
+	byName  map[string]int
+}
+
+func newPromptIndex(prompts []Prompt) promptIndex {
+	idx := promptIndex{prompts: prompts, byName: make(map[string]int)}
+	for i, p := range prompts {
+		for _, key := range append([]string{p.Name}, p.Aliases...) {
+			key = strings.ToLower(key)
+			if _, ok := idx.byName[key]; !ok {
+				idx.byName[key] = i
+			}
+		}
+	}
+	return idx
+}
+
+func (idx promptIndex) lookup(target string) (int, bool) {
+	target = strings.ToLower(strings.TrimSpace(target))
+	if i, ok := idx.findTarget(target); ok {
+		return i, true
+	}
+	// Links may spell out the file extension: [[review.md]].
+	if ext := filepath.Ext(target); ext != "" {
+		return idx.findTarget(strings.TrimSuffix(target, ext))
+	}
+	return 0, false
+}
+
+func (idx promptIndex) findTarget(target string) (int, bool) {
+	if i, ok := idx.byName[target]; ok {
+		return i, true
+	}
+	if !strings.Contains(target, "/") {
+		return 0, false
+	}
+	for i, p := range idx.prompts {
+		path := strings.ToLower(filepath.ToSlash(p.Path))
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+		if strings.HasSuffix(path, "/"+target) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// resolveLinks rewrites wikilink targets to the names of the prompts they
+// point to and expands ![[embeds]] of other prompts in place. Embeds that
+// cannot be resolved, or that would recurse into themselves, are left as
+// written.
+func resolveLinks(prompts []Prompt) []Prompt {
+	idx := newPromptIndex(prompts)
+	resolved := make([]Prompt, len(prompts))
+	for i, p := range prompts {
+		if len(p.Links) > 0 {
+			links := make([]string, len(p.Links))
+			for j, link := range p.Links {
+				links[j] = link
+				if target, ok := idx.lookup(link); ok {
+					links[j] = prompts[target].Name
+				}
+			}
+			p.Links = cleanSlice(links)
+		}
+		if strings.Contains(p.Content, "![[") {
+			p.Content = expandEmbeds(idx, i, p.Content, map[string]bool{fmt.Sprintf("%d#", i): true})
+		}
+		resolved[i] = p
+	}
+	return resolved
+}
+
+// expandEmbeds replaces ![[embeds]] in content. active holds the prompt and
+// heading pairs being expanded further up, which are left alone to break
+// cycles.
+func expandEmbeds(idx promptIndex, self int, content string, active map[string]bool) string {
+	return wikilinkPattern.ReplaceAllStringFunc(content, func(link string) string {
+		match := wikilinkPattern.FindStringSubmatch(link)
+		if match[1] != "!" {
+			return link
+		}
+		target, ok := self, true
+		name, heading := strings.TrimSpace(match[2]), strings.TrimSpace(match[3])
+		if name != "" && heading != "" {
+			// A split section is a prompt of its own: ![[file#Heading]].
+			if section, found := idx.lookup(name + SectionSeparator + heading); found {
+				target, heading = section, ""
+			} else {
+				target, ok = idx.lookup(name)
+			}
+		} else if name != "" {
+			target, ok = idx.lookup(name)
+		}
+		key := fmt.Sprintf("%d#%s", target, strings.ToLower(heading))
+		if !ok || active[key] {
+			return link
+		}
+
+		embedded := idx.prompts[target].Content
+		if heading != "" {
+			section, found := sectionUnder(embedded, heading)
+			if !found {
+				return link
+			}
+			embedded = section
+		}
+
+		active[key] = true
+		defer delete(active, key)
+		return strings.TrimRight(expandEmbeds(idx, target, embedded, active), "\n")
+	})
+}
+
+// sectionUnder returns the text below the Markdown heading named heading, up
+// to the next heading of the same or a higher level.
+func sectionUnder(content, heading string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	start, level := -1, 0
+	for i, line := range lines {
+		depth, title := headingLevel(line)
+		if depth == 0 {
+			continue
+		}
+		if start >= 0 && depth <= level {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n")), true
+		}
+		if start < 0 && strings.EqualFold(title, heading) {
+			start, level = i+1, depth
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n")), true
+}
+
+// headingLevel returns the depth and text of an ATX heading, or 0.
+func headingLevel(line string) (int, string) {
+	depth := 0
+	for depth < len(line) && line[depth] == '#' {
+		depth++
+	}
+	if depth == 0 || depth > 6 || depth == len(line) || line[depth] != ' ' {
+		return 0, ""
+	}
+	return depth, strings.TrimSpace(line[depth:])
+}
@@ -0,0 +1,93 @@
+package prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildPromptReadsObsidianMetadata(t *testing.T) {
+	data := "---\ntag: \"#project/alpha, draft\"\nalias: Launch Plan\n---\n" +
+		"# Heading\nPlan the #launch with #team/design. See [[review]] and [[notes/Ideas|ideas]].\n" +
+		"Issue #123 is not a tag, nor is `#code` or a url#fragment.\n" +
+		"```\n#not-a-tag [[not-a-link]]\n```\n![[snippet]]\n"
+
+	p, err := buildPrompt("plan.md", []byte(data))
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+
+	if got := strings.Join(p.Tags, ","); got != "project/alpha,draft,launch,team/design" {
+		t.Errorf("unexpected tags %q", got)
+	}
+	if got := strings.Join(p.Aliases, ","); got != "Launch Plan" {
+		t.Errorf("unexpected aliases %q", got)
+	}
+	if got := strings.Join(p.Links, ","); got != "review,notes/Ideas" {
+		t.Errorf("unexpected links %q", got)
+	}
+}
+
+func TestHasTagMatchesSubtags(t *testing.T) {
+	p := Prompt{Tags: []string{"Project/Alpha", "draft"}}
+	for tag, want := range map[string]bool{
+		"project":        true,
+		"#project/alpha": true,
+		"project/beta":   false,
+		"proj":           false,
+		"draft":          true,
+		"":               false,
+	} {
+		if got := p.HasTag(tag); got != want {
+			t.Errorf("HasTag(%q) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestLoadResolvesWikilinksAndEmbeds(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"notes", ".obsidian", ".trash"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, "main.md"), "Start\n![[persona]]\n![[notes/style#Tone]]\n![[missing]]\nSee [[Reviewer]].\n")
+	writeTestFile(t, filepath.Join(dir, "persona.md"), "---\naliases: [Reviewer]\n---\nYou are a reviewer.\n![[main]]\n")
+	writeTestFile(t, filepath.Join(dir, "notes", "style.md"), "# Style\n## Tone\nBe direct.\n## Length\nBe brief.\n")
+	writeTestFile(t, filepath.Join(dir, ".obsidian", "workspace.md"), "internal\n")
+	writeTestFile(t, filepath.Join(dir, ".trash", "old.md"), "deleted\n")
+
+	prompts, _, err := LoadContext(context.Background(), []string{dir}, Options{Extensions: []string{".md"}})
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	found := make(map[string]Prompt)
+	for _, p := range prompts {
+		found[p.Name] = p
+	}
+	if len(found) != 3 {
+		t.Fatalf("expected vault metadata and trash to be skipped, got %d prompts", len(found))
+	}
+
+	main := found["main"]
+	want := "Start\nYou are a reviewer.\n![[main]]\nBe direct.\n![[missing]]\nSee [[Reviewer]].\n"
+	if main.Content != want {
+		t.Errorf("unexpected expansion:\n%q\nwant\n%q", main.Content, want)
+	}
+	if len(main.Links) != 1 || main.Links[0] != "persona" {
+		t.Errorf("expected the alias link to resolve to persona, got %v", main.Links)
+	}
+	if !strings.Contains(found["persona"].Content, "Start\n![[persona]]") {
+		t.Errorf("expected the embed cycle to stop at persona, got %q", found["persona"].Content)
+	}
+
+	prompts, _, err = LoadContext(context.Background(), []string{dir}, Options{Extensions: []string{".md"}, IgnorePatterns: []string{"!.trash/"}})
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if len(prompts) != 4 {
+		t.Fatalf("expected .trash to be re-included, got %d prompts", len(prompts))
+	}
+}
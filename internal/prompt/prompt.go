@@ -1,125 +1,141 @@
 package prompt
 
 import (
-	"bufio"
-	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"io"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
-
-	"gopkg.in/yaml.v3"
+	"time"
+	"unicode/utf8"
 )
 
+// ErrInvalidEncoding is reported for prompt files that are not valid UTF-8.
+var ErrInvalidEncoding = errors.New("file is not valid UTF-8")
+
 // Prompt represents a prompt file and its derived metadata.
 type Prompt struct {
-	Name        string
-	Path        string
-	Content     string
-	FrontMatter map[string]any
-	Tags        []string
+	Name        string         `json:"name"`
+	Path        string         `json:"path"`
+	Content     string         `json:"content"`
+	FrontMatter map[string]any `json:"front_matter,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`
+	// Aliases are alternative names from the aliases (or alias) front matter.
+	Aliases []string `json:"aliases,omitempty"`
+	// Links are the prompts referenced by [[wikilinks]] in the content.
+	Links []string `json:"links,omitempty"`
+	// Messages, Model, ModelParameters and Inputs are set for structured
+	// .prompt.yaml and .prompt.json files.
+	Messages        []Message      `json:"messages,omitempty"`
+	Model           string         `json:"model,omitempty"`
+	ModelParameters map[string]any `json:"model_parameters,omitempty"`
+	Inputs          []Input        `json:"inputs,omitempty"`
+	// Source names where the prompt's directory comes from, such as the git
+	// repository it is synced from. It is empty for ordinary directories.
+	Source string `json:"source,omitempty"`
+	// ReadOnly marks prompts that pm must not write to, because their
+	// directory is managed elsewhere.
+	ReadOnly bool      `json:"read_only,omitempty"`
+	ModTime  time.Time `json:"modified"`
+}
+
+// Source describes a prompt directory whose prompts carry an origin, and
+// the discovery options that differ for it. Extensions and MaxFileSize replace
+// the Options values when set; IgnorePatterns are added to them.
+type Source struct {
+	Label          string
+	ReadOnly       bool
+	Extensions     []string
+	IgnorePatterns []string
+	MaxFileSize    int64 // bytes
 }
 
 // Options configure prompt discovery.
 type Options struct {
-	Extensions     []string
+	Extensions []string
+	// IgnorePatterns use gitignore syntax relative to each prompt directory.
 	IgnorePatterns []string
 	MaxFileSize    int64 // bytes
+	// UseGitignore also honours .gitignore files alongside .pmignore.
+	UseGitignore bool
+	// FollowSymlinks descends into symlinked directories, skipping loops and
+	// directories already reached through another path.
+	FollowSymlinks bool
+	// SplitSections turns each "## " section of a file into its own prompt.
+	SplitSections bool
+	// Discovery limits loading to files that look like prompts.
+	Discovery Discovery
+	// Sources label the prompts of some directories, keyed by the directory
+	// as passed to LoadContext or NewLibrary.
+	Sources map[string]Source
+	// Workers bounds how many files are read and parsed concurrently.
+	// Defaults to GOMAXPROCS.
+	Workers int
 }
 
 // LoadFromDirs discovers prompt files under the provided directories using the supplied options.
+// Files that cannot be loaded are skipped; use LoadContext to find out which.
 func LoadFromDirs(dirs []string, opts Options) ([]Prompt, error) {
-	var prompts []Prompt
-	seen := make(map[string]struct{})
-
-	for _, dir := range dirs {
-		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, walkErr error) error {
-			if walkErr != nil {
-				return walkErr
-			}
-
-			if d.IsDir() {
-				if shouldIgnore(path, opts.IgnorePatterns) {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-
-			if shouldIgnore(path, opts.IgnorePatterns) {
-				return nil
-			}
-
-			if len(opts.Extensions) > 0 && !hasAllowedExtension(path, opts.Extensions) {
-				return nil
-			}
-
-			if opts.MaxFileSize > 0 && d.Type().IsRegular() {
-				info, err := d.Info()
-				if err != nil {
-					return err
-				}
-				if info.Size() > opts.MaxFileSize {
-					return nil
-				}
-			}
-
-			absPath, err := filepath.Abs(path)
-			if err != nil {
-				return err
-			}
-
-			if _, ok := seen[absPath]; ok {
-				return nil
-			}
-
-			fileBytes, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
-
-			prompt, err := buildPrompt(path, fileBytes)
-			if err != nil {
-				return err
-			}
-
-			prompts = append(prompts, prompt)
-			seen[absPath] = struct{}{}
-
-			return nil
-		})
+	prompts, _, err := LoadContext(context.Background(), dirs, opts)
+	return prompts, err
+}
 
-		if err != nil && !errors.Is(err, os.ErrNotExist) {
-			return nil, err
-		}
+// forRoot returns the options in effect below root, with the overrides of
+// its source applied.
+func (o Options) forRoot(root string) Options {
+	source, ok := o.Sources[root]
+	if !ok {
+		return o
+	}
+	if len(source.Extensions) > 0 {
+		o.Extensions = source.Extensions
 	}
+	if len(source.IgnorePatterns) > 0 {
+		o.IgnorePatterns = append(append([]string(nil), o.IgnorePatterns...), source.IgnorePatterns...)
+	}
+	if source.MaxFileSize > 0 {
+		o.MaxFileSize = source.MaxFileSize
+	}
+	return o
+}
 
-	return prompts, nil
+// acceptFile applies the extension and size limits to a candidate file.
+func acceptFile(path string, info os.FileInfo, opts Options) bool {
+	if !acceptExtension(path, opts.Extensions) {
+		return false
+	}
+	if opts.MaxFileSize > 0 && info.Mode().IsRegular() && info.Size() > opts.MaxFileSize {
+		return false
+	}
+	return true
 }
 
-func shouldIgnore(filePath string, patterns []string) bool {
-	base := filepath.Base(filePath)
-	slashPath := filepath.ToSlash(filePath)
-	for _, pattern := range patterns {
-		matched, err := filepath.Match(pattern, base)
-		if err == nil && matched {
-			return true
-		}
-		normalizedPattern := filepath.ToSlash(pattern)
-		matched, err = path.Match(normalizedPattern, slashPath)
-		if err == nil && matched {
-			return true
-		}
+// readPromptFile reads a prompt file, rejecting content that is not UTF-8.
+func readPromptFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-	return false
+	if !utf8.Valid(data) {
+		return nil, ErrInvalidEncoding
+	}
+	return data, nil
+}
+
+// acceptExtension reports whether path has one of the extensions, or any when
+// none are configured.
+func acceptExtension(path string, extensions []string) bool {
+	return len(extensions) == 0 || hasAllowedExtension(path, extensions)
 }
 
+// hasAllowedExtension matches whole name suffixes, so that extensions such as
+// ".prompt.yaml" work as well as ".md".
 func hasAllowedExtension(path string, extensions []string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
+	base := strings.ToLower(filepath.Base(path))
 	for _, allowed := range extensions {
-		if strings.ToLower(allowed) == ext {
+		allowed = strings.ToLower(allowed)
+		if allowed != "" && strings.HasSuffix(base, allowed) && len(base) > len(allowed) {
 			return true
 		}
 	}
@@ -130,7 +146,7 @@ func buildPrompt(path string, data []byte) (Prompt, error) {
 	frontMatter, content := parseFrontMatter(data)
 	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 
-	tags := extractTags(frontMatter)
+	tags := unique(append(extractTags(frontMatter), extractInlineTags(content)...))
 
 	return Prompt{
 		Name:        name,
@@ -138,90 +154,82 @@ func buildPrompt(path string, data []byte) (Prompt, error) {
 		Content:     content,
 		FrontMatter: frontMatter,
 		Tags:        tags,
+		Aliases:     extractAliases(frontMatter),
+		Links:       extractLinks(content),
 	}, nil
 }
 
-func parseFrontMatter(data []byte) (map[string]any, string) {
-	reader := bufio.NewReader(bytes.NewReader(data))
-
-	firstLine, err := reader.ReadString('\n')
-	if err != nil && !errors.Is(err, io.EOF) {
-		return nil, string(data)
+// extractTags reads the tags (or Obsidian's older tag) front matter key.
+// Obsidian accepts a leading "#" on tags written there, which is dropped.
+func extractTags(front map[string]any) []string {
+	tags := frontMatterList(front, "tags", "tag")
+	for i, tag := range tags {
+		tags[i] = strings.TrimPrefix(tag, "#")
 	}
+	return cleanSlice(tags)
+}
 
-	if strings.TrimSpace(firstLine) != "---" {
-		return nil, string(data)
-	}
+// extractAliases reads the aliases (or alias) front matter key.
+func extractAliases(front map[string]any) []string {
+	return frontMatterList(front, "aliases", "alias")
+}
 
-	var buf strings.Builder
-	for {
-		line, err := reader.ReadString('\n')
-		if errors.Is(err, io.EOF) {
-			return nil, string(data)
+// FrontMatterString returns the front matter value of the first of keys that
+// is set to something other than blank, as trimmed text. Lists are joined with
+// commas.
+func (p Prompt) FrontMatterString(keys ...string) string {
+	for _, key := range keys {
+		var text string
+		switch v := p.FrontMatter[key].(type) {
+		case nil:
+			continue
+		case string:
+			text = v
+		case []any:
+			parts := make([]string, 0, len(v))
+			for _, item := range v {
+				parts = append(parts, fmt.Sprint(item))
+			}
+			text = strings.Join(parts, ", ")
+		default:
+			text = fmt.Sprint(v)
 		}
-
-		if strings.TrimSpace(line) == "---" {
-			break
+		if text = strings.TrimSpace(text); text != "" {
+			return text
 		}
-
-		buf.WriteString(line)
 	}
-
-	raw := buf.String()
-	if strings.TrimSpace(raw) == "" {
-		rest, _ := io.ReadAll(reader)
-		content := strings.TrimLeft(string(rest), "\r\n")
-		return nil, content
-	}
-
-	var front map[string]any
-	if err := yaml.Unmarshal([]byte(raw), &front); err != nil {
-		// If parsing fails, fall back to treating the data as raw content.
-		return nil, string(data)
-	}
-
-	rest, _ := io.ReadAll(reader)
-	content := strings.TrimLeft(string(rest), "\r\n")
-
-	return normalizeFrontMatter(front), content
-}
-
-func normalizeFrontMatter(input map[string]any) map[string]any {
-	if input == nil {
-		return nil
-	}
-
-	normalized := make(map[string]any, len(input))
-	for key, value := range input {
-		normalized[strings.TrimSpace(key)] = value
-	}
-	return normalized
+	return ""
 }
 
-func extractTags(front map[string]any) []string {
+// frontMatterList returns the values of the first key present, accepting a
+// list or a comma separated string.
+func frontMatterList(front map[string]any, keys ...string) []string {
 	if front == nil {
 		return nil
 	}
 
-	raw, ok := front["tags"]
-	if !ok || raw == nil {
-		return nil
-	}
+	for _, key := range keys {
+		raw, ok := front[key]
+		if !ok || raw == nil {
+			continue
+		}
 
-	switch v := raw.(type) {
-	case string:
-		return splitAndClean(v)
-	case []string:
-		return cleanSlice(v)
-	case []any:
-		var tags []string
-		for _, item := range v {
-			tags = append(tags, splitAndClean(fmt.Sprint(item))...)
+		switch v := raw.(type) {
+		case string:
+			return splitAndClean(v)
+		case []string:
+			return cleanSlice(v)
+		case []any:
+			var values []string
+			for _, item := range v {
+				values = append(values, splitAndClean(fmt.Sprint(item))...)
+			}
+			return unique(values)
+		default:
+			return splitAndClean(fmt.Sprint(v))
 		}
-		return unique(tags)
-	default:
-		return splitAndClean(fmt.Sprint(v))
 	}
+	return nil
 }
 
 func splitAndClean(value string) []string {
@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -90,6 +91,26 @@ func TestLoadFromDirsRespectsExtensions(t *testing.T) {
 	}
 }
 
+func TestFrontMatterString(t *testing.T) {
+	p := Prompt{FrontMatter: map[string]any{
+		"summary":     "  ",
+		"description": " Review a diff \n",
+		"owners":      []any{"docs", "infra"},
+		"priority":    2,
+	}}
+
+	for keys, want := range map[string]string{
+		"summary,description": "Review a diff",
+		"owners":              "docs, infra",
+		"priority":            "2",
+		"missing":             "",
+	} {
+		if got := p.FrontMatterString(strings.Split(keys, ",")...); got != want {
+			t.Errorf("FrontMatterString(%s) = %q, want %q", keys, got, want)
+		}
+	}
+}
+
 func contentHasFrontMatter(content string) bool {
 	return len(content) > 0 && content[0] == '-'
 }
@@ -0,0 +1,109 @@
+package prompt
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SectionSeparator joins a file name and a section heading in the names of
+// prompts produced by Options.SplitSections.
+const SectionSeparator = "#"
+
+// sectionTagsPattern matches the per-section tag marker <!-- tags: a, b -->.
+var sectionTagsPattern = regexp.MustCompile(`(?m)^[ \t]*<!--\s*tags:\s*(.*?)\s*-->[ \t]*\n?`)
+
+type section struct {
+	heading string
+	body    string
+}
+
+// splitSections divides content at "## " headings outside code fences. Text
+// before the first heading is not part of any section.
+func splitSections(content string) []section {
+	var sections []section
+	var current *section
+	var body []string
+	fence := ""
+
+	flush := func() {
+		if current != nil {
+			current.body = strings.TrimSpace(strings.Join(body, "\n"))
+			sections = append(sections, *current)
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		default:
+			if depth, title := headingLevel(line); depth == 2 && title != "" {
+				flush()
+				current, body = &section{heading: title}, nil
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+	flush()
+	return sections
+}
+
+// buildPrompts parses a file into prompts. With SplitSections each "## "
+// section becomes its own prompt named file#heading that shares the file's
+// front matter and adds the tags from an optional <!-- tags: ... --> marker.
+// Files without such headings yield a single prompt.
+func buildPrompts(path string, data []byte, opts Options) ([]Prompt, error) {
+	if isStructuredPrompt(path) {
+		p, err := buildStructuredPrompt(path, data)
+		if err != nil {
+			return nil, err
+		}
+		return []Prompt{p}, nil
+	}
+
+	p, err := buildPrompt(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.SplitSections {
+		return []Prompt{p}, nil
+	}
+
+	sections := splitSections(p.Content)
+	if len(sections) == 0 {
+		return []Prompt{p}, nil
+	}
+
+	prompts := make([]Prompt, 0, len(sections))
+	for _, s := range sections {
+		tags := extractTags(p.FrontMatter)
+		for _, match := range sectionTagsPattern.FindAllStringSubmatch(s.body, -1) {
+			for _, tag := range splitAndClean(match[1]) {
+				tags = append(tags, strings.TrimPrefix(tag, "#"))
+			}
+		}
+		body := strings.TrimSpace(sectionTagsPattern.ReplaceAllString(s.body, ""))
+		// A file alias names each section the way the file name does.
+		var aliases []string
+		for _, alias := range p.Aliases {
+			aliases = append(aliases, alias+SectionSeparator+s.heading)
+		}
+
+		prompts = append(prompts, Prompt{
+			Name:        p.Name + SectionSeparator + s.heading,
+			Path:        p.Path,
+			Content:     body + "\n",
+			FrontMatter: p.FrontMatter,
+			Aliases:     aliases,
+			Tags:        unique(append(tags, extractInlineTags(body)...)),
+			Links:       extractLinks(body),
+		})
+	}
+	return prompts, nil
+}
@@ -0,0 +1,95 @@
+package prompt
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sectionedFile = `---
+tags: [writing]
+aliases: [prose]
+---
+# Writing prompts
+
+Shared notes that belong to no section.
+
+## Summarize
+<!-- tags: summary, #short -->
+Summarize the text below.
+
+## Translate
+Translate into {{language}}. #i18n
+
+` + "```" + `
+## not a heading inside code
+` + "```" + `
+`
+
+func TestBuildPromptsSplitsSections(t *testing.T) {
+	prompts, err := buildPrompts("family.md", []byte(sectionedFile), Options{SplitSections: true})
+	if err != nil {
+		t.Fatalf("buildPrompts() error = %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(prompts))
+	}
+
+	summarize, translate := prompts[0], prompts[1]
+	if summarize.Name != "family#Summarize" || translate.Name != "family#Translate" {
+		t.Fatalf("unexpected names %q and %q", summarize.Name, translate.Name)
+	}
+	if summarize.Content != "Summarize the text below.\n" {
+		t.Errorf("expected the tag marker to be stripped, got %q", summarize.Content)
+	}
+	if got := strings.Join(summarize.Aliases, ","); got != "prose#Summarize" {
+		t.Errorf("expected the file alias to name the section, got %q", got)
+	}
+	if got := strings.Join(summarize.Tags, ","); got != "writing,summary,short" {
+		t.Errorf("unexpected section tags %q", got)
+	}
+	if got := strings.Join(translate.Tags, ","); got != "writing,i18n" {
+		t.Errorf("unexpected section tags %q", got)
+	}
+	if !strings.Contains(translate.Content, "## not a heading inside code") {
+		t.Errorf("expected fenced headings to stay in the section, got %q", translate.Content)
+	}
+
+	whole, err := buildPrompts("family.md", []byte(sectionedFile), Options{})
+	if err != nil || len(whole) != 1 || whole[0].Name != "family" {
+		t.Fatalf("expected one prompt without SplitSections, got %v (%v)", whole, err)
+	}
+	plain, err := buildPrompts("plain.md", []byte("# Only a title\nBody\n"), Options{SplitSections: true})
+	if err != nil || len(plain) != 1 || plain[0].Name != "plain" {
+		t.Fatalf("expected files without sections to stay whole, got %v (%v)", plain, err)
+	}
+}
+
+func TestLibraryKeepsSectionsTogether(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.md"), "Alpha\n")
+	writeTestFile(t, filepath.Join(dir, "family.md"), sectionedFile)
+	writeTestFile(t, filepath.Join(dir, "uses.md"), "![[family#Translate]]\n")
+
+	opts := Options{SplitSections: true}
+	prompts, _, err := LoadContext(context.Background(), []string{dir}, opts)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	lib, err := NewLibrary([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("NewLibrary() error = %v", err)
+	}
+	assertLibraryNames(t, lib, "a", "family#Summarize", "family#Translate", "uses")
+	if len(prompts) != 4 || prompts[3].Content != lib.Prompts()[3].Content {
+		t.Fatalf("expected LoadContext and Library to agree, got %v", prompts)
+	}
+	if !strings.HasPrefix(prompts[3].Content, "Translate into {{language}}.") {
+		t.Fatalf("expected the section embed to expand, got %q", prompts[3].Content)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "family.md"), "## Only\nOne section left.\n")
+	lib.Refresh(filepath.Join(dir, "family.md"))
+	assertLibraryNames(t, lib, "a", "family#Only", "uses")
+}
@@ -0,0 +1,164 @@
+package prompt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Structured prompt files describe chat prompts with separate roles.
+const (
+	StructuredYAMLSuffix = ".prompt.yaml"
+	StructuredJSONSuffix = ".prompt.json"
+)
+
+// Message roles accepted in structured prompt files.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one chat message of a structured prompt.
+type Message struct {
+	Role    string `json:"role" yaml:"role"`
+	Content string `json:"content" yaml:"content"`
+}
+
+// Input declares a template variable of a structured prompt.
+type Input struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Default     string `json:"default,omitempty" yaml:"default"`
+	Required    bool   `json:"required,omitempty" yaml:"required"`
+}
+
+// structuredFile is the schema of .prompt.yaml and .prompt.json files. Every
+// other top-level key, such as description, tags or aliases, is kept as
+// front matter.
+type structuredFile struct {
+	Model           string         `json:"model" yaml:"model"`
+	ModelParameters map[string]any `json:"modelParameters" yaml:"modelParameters"`
+	Variables       []Input        `json:"variables" yaml:"variables"`
+	Messages        []Message      `json:"messages" yaml:"messages"`
+}
+
+// isStructuredPrompt reports whether path names a structured prompt file.
+func isStructuredPrompt(path string) bool {
+	return structuredSuffix(path) != ""
+}
+
+func structuredSuffix(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	for _, suffix := range []string{StructuredYAMLSuffix, ".prompt.yml", StructuredJSONSuffix} {
+		if strings.HasSuffix(base, suffix) && len(base) > len(suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// buildStructuredPrompt parses a structured prompt file. Its content is the
+// flattened conversation, so search, cat and mesh work on it like any other
+// prompt, while Messages keeps the roles.
+func buildStructuredPrompt(path string, data []byte) (Prompt, error) {
+	unmarshal := yaml.Unmarshal
+	if structuredSuffix(path) == StructuredJSONSuffix {
+		unmarshal = json.Unmarshal
+	}
+
+	var file structuredFile
+	if err := unmarshal(data, &file); err != nil {
+		return Prompt{}, fmt.Errorf("parse structured prompt: %w", err)
+	}
+	if len(file.Messages) == 0 {
+		return Prompt{}, errors.New("structured prompt has no messages")
+	}
+	for i, message := range file.Messages {
+		role := strings.ToLower(strings.TrimSpace(message.Role))
+		switch role {
+		case RoleSystem, RoleUser, RoleAssistant:
+		default:
+			return Prompt{}, fmt.Errorf("message %d: unknown role %q", i+1, message.Role)
+		}
+		file.Messages[i].Role = role
+	}
+	for i, input := range file.Variables {
+		if strings.TrimSpace(input.Name) == "" {
+			return Prompt{}, fmt.Errorf("variable %d has no name", i+1)
+		}
+		file.Variables[i].Name = strings.TrimSpace(input.Name)
+	}
+
+	var front map[string]any
+	if err := unmarshal(data, &front); err != nil {
+		return Prompt{}, fmt.Errorf("parse structured prompt: %w", err)
+	}
+	for _, key := range []string{"model", "modelParameters", "variables", "messages"} {
+		delete(front, key)
+	}
+	front = normalizeFrontMatter(front)
+	if len(front) == 0 {
+		front = nil
+	}
+
+	content := flattenMessages(file.Messages)
+	base := filepath.Base(path)
+	return Prompt{
+		Name:            base[:len(base)-len(structuredSuffix(path))],
+		Path:            path,
+		Content:         content,
+		FrontMatter:     front,
+		Tags:            unique(append(extractTags(front), extractInlineTags(content)...)),
+		Aliases:         extractAliases(front),
+		Links:           extractLinks(content),
+		Messages:        file.Messages,
+		Model:           strings.TrimSpace(file.Model),
+		ModelParameters: normalizeFrontMatter(file.ModelParameters),
+		Inputs:          file.Variables,
+	}, nil
+}
+
+// flattenMessages renders a conversation as text. A lone user message is
+// returned as is; otherwise each message is introduced by its role.
+func flattenMessages(messages []Message) string {
+	if len(messages) == 1 && messages[0].Role == RoleUser {
+		return strings.TrimSpace(messages[0].Content) + "\n"
+	}
+	parts := make([]string, 0, len(messages))
+	for _, message := range messages {
+		parts = append(parts, message.Role+":\n"+strings.TrimSpace(message.Content))
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// ChatMessages returns the prompt as chat messages: the messages of a
+// structured prompt, or its content as a single user message.
+func (p Prompt) ChatMessages() []Message {
+	if len(p.Messages) > 0 {
+		return append([]Message(nil), p.Messages...)
+	}
+	return []Message{{Role: RoleUser, Content: p.Content}}
+}
+
+// Values returns values completed with the defaults of the prompt's declared
+// inputs.
+func (p Prompt) Values(values map[string]string) map[string]string {
+	if len(p.Inputs) == 0 {
+		return values
+	}
+	merged := make(map[string]string, len(values)+len(p.Inputs))
+	for _, input := range p.Inputs {
+		if input.Default != "" {
+			merged[input.Name] = input.Default
+		}
+	}
+	for name, value := range values {
+		merged[name] = value
+	}
+	return merged
+}
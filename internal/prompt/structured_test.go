@@ -0,0 +1,132 @@
+package prompt
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const structuredYAML = `description: Review a diff
+tags: [review]
+model: gpt-4o-mini
+modelParameters:
+  temperature: 0.2
+variables:
+  - name: language
+    description: Language of the diff
+    default: Go
+messages:
+  - role: system
+    content: You review {{language}} code.
+  - role: User
+    content: |
+      Review this diff:
+      {{diff}}
+`
+
+func TestBuildStructuredPromptYAML(t *testing.T) {
+	p, err := buildStructuredPrompt("review.prompt.yaml", []byte(structuredYAML))
+	if err != nil {
+		t.Fatalf("buildStructuredPrompt() error = %v", err)
+	}
+
+	if p.Name != "review" {
+		t.Errorf("expected name review, got %q", p.Name)
+	}
+	wantMessages := []Message{
+		{Role: RoleSystem, Content: "You review {{language}} code."},
+		{Role: RoleUser, Content: "Review this diff:\n{{diff}}\n"},
+	}
+	if !reflect.DeepEqual(p.Messages, wantMessages) {
+		t.Errorf("messages = %#v", p.Messages)
+	}
+	if p.Content != "system:\nYou review {{language}} code.\n\nuser:\nReview this diff:\n{{diff}}\n" {
+		t.Errorf("unexpected flattened content %q", p.Content)
+	}
+	if p.Model != "gpt-4o-mini" || p.ModelParameters["temperature"] != 0.2 {
+		t.Errorf("unexpected model hints %q %v", p.Model, p.ModelParameters)
+	}
+	if !reflect.DeepEqual(p.FrontMatter, map[string]any{"description": "Review a diff", "tags": []any{"review"}}) {
+		t.Errorf("front matter = %#v", p.FrontMatter)
+	}
+	if !p.HasTag("review") {
+		t.Errorf("expected review tag, got %v", p.Tags)
+	}
+
+	values := p.Values(map[string]string{"diff": "+x"})
+	if values["language"] != "Go" || values["diff"] != "+x" {
+		t.Errorf("expected defaults merged under values, got %v", values)
+	}
+	if got := p.Values(map[string]string{"language": "Rust"})["language"]; got != "Rust" {
+		t.Errorf("expected explicit value to win, got %q", got)
+	}
+}
+
+func TestBuildStructuredPromptJSON(t *testing.T) {
+	data := `{"messages": [{"role": "user", "content": "Summarize {{text}}"}], "aliases": ["tldr"]}`
+	p, err := buildStructuredPrompt("summary.prompt.json", []byte(data))
+	if err != nil {
+		t.Fatalf("buildStructuredPrompt() error = %v", err)
+	}
+	if p.Name != "summary" || p.Content != "Summarize {{text}}\n" {
+		t.Errorf("unexpected prompt %q with content %q", p.Name, p.Content)
+	}
+	if !reflect.DeepEqual(p.Aliases, []string{"tldr"}) {
+		t.Errorf("aliases = %v", p.Aliases)
+	}
+	if got := p.ChatMessages(); len(got) != 1 || got[0].Role != RoleUser {
+		t.Errorf("unexpected chat messages %v", got)
+	}
+}
+
+func TestBuildStructuredPromptRejectsInvalidFiles(t *testing.T) {
+	tests := map[string]string{
+		"no messages":  "description: empty\n",
+		"unknown role": "messages:\n  - role: tool\n    content: x\n",
+		"unnamed":      "variables:\n  - default: x\nmessages:\n  - role: user\n    content: x\n",
+		"invalid yaml": "messages: [\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := buildStructuredPrompt("bad.prompt.yaml", []byte(data)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestLoadContextIncludesStructuredPrompts(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "plain.md"), "Plain\n")
+	writeTestFile(t, filepath.Join(dir, "review.prompt.yaml"), structuredYAML)
+	writeTestFile(t, filepath.Join(dir, "broken.prompt.json"), `{"messages": []}`)
+	writeTestFile(t, filepath.Join(dir, "settings.yaml"), "key: value\n")
+
+	opts := Options{Extensions: []string{".prompt.yaml", ".prompt.json"}}
+	prompts, diagnostics, err := LoadContext(t.Context(), []string{dir}, opts)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "review" || len(prompts[0].Messages) != 2 {
+		t.Fatalf("expected only the structured prompt, got %v", prompts)
+	}
+	if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Error(), "no messages") {
+		t.Fatalf("expected a diagnostic for the broken file, got %v", diagnostics)
+	}
+
+	for name, opts := range map[string]Options{
+		"extensions": {Extensions: []string{".md"}},
+		"discovery":  {Extensions: []string{".md", ".prompt.yaml"}, Discovery: Discovery{Paths: []string{"prompts/"}}},
+	} {
+		prompts, _, err := LoadContext(t.Context(), []string{dir}, opts)
+		if err != nil {
+			t.Fatalf("LoadContext() error = %v", err)
+		}
+		for _, p := range prompts {
+			if len(p.Messages) > 0 {
+				t.Errorf("expected %s to filter structured prompts like other files, got %v", name, p.Path)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package prompt
+
+import (
+	"regexp"
+	"strings"
+)
+
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// Variables returns the names of {{variable}} placeholders in content in order
+// of first appearance.
+func Variables(content string) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, match := range variablePattern.FindAllStringSubmatch(content, -1) {
+		name := match[1]
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Render substitutes {{variable}} placeholders with the provided values.
+// Placeholders without a value are left untouched.
+func Render(content string, values map[string]string) string {
+	if len(values) == 0 || !strings.Contains(content, "{{") {
+		return content
+	}
+	return variablePattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
@@ -0,0 +1,26 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVariablesListsPlaceholdersOnce(t *testing.T) {
+	content := "Review {{ language }} code for {{audience}}.\nFocus on {{language}} idioms. Ignore {{ 1bad }}."
+
+	got := Variables(content)
+	want := []string{"language", "audience"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected variables %v, got %v", want, got)
+	}
+}
+
+func TestRenderSubstitutesKnownValues(t *testing.T) {
+	content := "Review {{ language }} code for {{audience}}."
+
+	got := Render(content, map[string]string{"language": "Go"})
+	want := "Review Go code for {{audience}}."
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
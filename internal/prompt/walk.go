@@ -0,0 +1,120 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSymlinkLoop is reported for a symlinked directory that points back at
+// one of its own ancestors.
+var ErrSymlinkLoop = errors.New("symlink loop")
+
+// treeWalker walks prompt directories with the semantics of filepath.WalkDir.
+// When follow is set it also descends into symlinked directories, visiting
+// each real directory once so that links shared between vaults or pointing
+// back up the tree neither duplicate prompts nor recurse forever.
+type treeWalker struct {
+	follow  bool
+	visited map[fileID]string // directory identity -> first path it was seen at
+}
+
+func newTreeWalker(follow bool) *treeWalker {
+	return &treeWalker{follow: follow, visited: make(map[fileID]string)}
+}
+
+// Walk calls fn for root and everything below it, like filepath.WalkDir.
+func (w *treeWalker) Walk(root string, fn fs.WalkDirFunc) error {
+	info, err := os.Lstat(root)
+	if err == nil && w.follow && info.Mode()&fs.ModeSymlink != 0 {
+		info, err = os.Stat(root)
+	}
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walkDir(root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (w *treeWalker) walkDir(path string, d fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if err == filepath.SkipDir && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	if w.follow {
+		if skip, err := w.enter(path, d); skip {
+			if err != nil {
+				err = fn(path, d, err)
+				if err == filepath.SkipDir {
+					err = nil
+				}
+			}
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		err = fn(path, d, err)
+		if err != nil {
+			if err == filepath.SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		if w.follow && entry.Type()&fs.ModeSymlink != 0 {
+			// Dangling links keep their symlink entry and fail when read.
+			if target, err := os.Stat(child); err == nil {
+				entry = fs.FileInfoToDirEntry(target)
+			}
+		}
+		if err := w.walkDir(child, entry, fn); err != nil {
+			if err == filepath.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// enter records the directory at path and reports whether it has already been
+// walked. Revisiting an ancestor is a loop and comes back as an error; any other
+// revisit is a second link to the same directory and is skipped silently.
+func (w *treeWalker) enter(path string, d fs.DirEntry) (bool, error) {
+	info, err := d.Info()
+	if err != nil {
+		return false, nil
+	}
+	id, ok := fileIdentity(path, info)
+	if !ok {
+		return false, nil
+	}
+	if first, seen := w.visited[id]; seen {
+		if isWithin(absPath(path), absPath(first)) {
+			return true, fmt.Errorf("%w back to %s", ErrSymlinkLoop, first)
+		}
+		return true, nil
+	}
+	w.visited[id] = path
+	return false, nil
+}
+
+// isWithin reports whether path is dir or lies below it.
+func isWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,167 @@
+//go:build linux
+
+package prompt
+
+import (
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF | syscall.IN_ATTRIB
+
+// inotifyBackend reports changes using Linux inotify watches on every
+// directory of the library.
+type inotifyBackend struct {
+	file   *os.File
+	fd     int
+	roots  []string
+	events chan string
+	done   chan struct{}
+	once   sync.Once
+
+	mu      sync.Mutex
+	watches map[int32]string
+}
+
+func newNativeBackend(roots, dirs []string) (watchBackend, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &inotifyBackend{
+		// A non-blocking descriptor lets Close interrupt a pending Read.
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		fd:      fd,
+		roots:   roots,
+		events:  make(chan string),
+		done:    make(chan struct{}),
+		watches: make(map[int32]string),
+	}
+	for _, dir := range dirs {
+		if err := b.add(dir); err != nil {
+			b.file.Close()
+			return nil, err
+		}
+	}
+
+	go b.loop()
+	return b, nil
+}
+
+func (b *inotifyBackend) Events() <-chan string { return b.events }
+
+func (b *inotifyBackend) Close() error {
+	b.once.Do(func() { close(b.done) })
+	return b.file.Close()
+}
+
+func (b *inotifyBackend) add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(b.fd, dir, inotifyMask)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.watches[int32(wd)] = dir
+	b.mu.Unlock()
+	return nil
+}
+
+// addTree subscribes to a directory that appeared after watching started.
+func (b *inotifyBackend) addTree(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr == nil && d.IsDir() {
+			b.add(path)
+		}
+		return nil
+	})
+}
+
+func (b *inotifyBackend) loop() {
+	defer close(b.events)
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			wd := int32(binary.NativeEndian.Uint32(buf[offset:]))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := string(trimNUL(buf[nameStart : nameStart+nameLen]))
+			offset = nameStart + nameLen
+
+			if !b.dispatch(wd, mask, name) {
+				return
+			}
+		}
+	}
+}
+
+// dispatch turns one inotify event into the paths to refresh. It reports
+// false once the backend is closed.
+func (b *inotifyBackend) dispatch(wd int32, mask uint32, name string) bool {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		// The kernel dropped events, including any for new directories, so
+		// subscribe again and rescan everything.
+		for _, root := range b.roots {
+			b.addTree(root)
+		}
+		for _, root := range b.roots {
+			if !b.send(root) {
+				return false
+			}
+		}
+		return true
+	}
+
+	b.mu.Lock()
+	dir, ok := b.watches[wd]
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(b.watches, wd)
+	}
+	b.mu.Unlock()
+	if !ok {
+		return true
+	}
+
+	path := dir
+	if name != "" {
+		path = filepath.Join(dir, name)
+	}
+	if mask&syscall.IN_ISDIR != 0 && mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		b.addTree(path)
+	}
+	if mask&syscall.IN_IGNORED != 0 && name == "" {
+		return true
+	}
+	return b.send(path)
+}
+
+func (b *inotifyBackend) send(path string) bool {
+	select {
+	case b.events <- path:
+		return true
+	case <-b.done:
+		return false
+	}
+}
+
+func trimNUL(name []byte) []byte {
+	for i, c := range name {
+		if c == 0 {
+			return name[:i]
+		}
+	}
+	return name
+}
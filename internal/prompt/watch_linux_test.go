@@ -0,0 +1,25 @@
+//go:build linux
+
+package prompt
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestInotifyOverflowRescansRoots(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	backend, err := newNativeBackend([]string{first, second}, []string{first, second})
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	b := backend.(*inotifyBackend)
+	defer b.Close()
+
+	go b.dispatch(-1, syscall.IN_Q_OVERFLOW, "")
+	for _, want := range []string{first, second} {
+		if got := <-b.events; got != want {
+			t.Fatalf("expected a rescan of %s, got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package prompt
+
+import "errors"
+
+// newNativeBackend is unavailable on this platform; Watch falls back to polling.
+func newNativeBackend(roots, dirs []string) (watchBackend, error) {
+	return nil, errors.New("native file watching not supported")
+}
@@ -0,0 +1,138 @@
+// Package remote keeps prompt directories that live elsewhere, such as git
+// repositories, in a local cache so they load like any other directory.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// scpLike matches git's user@host:path shorthand for ssh URLs.
+var scpLike = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/]`)
+
+// Git is a prompt directory tracked in a git repository, optionally pinned to
+// a branch or tag.
+type Git struct {
+	URL string
+	Ref string
+}
+
+// ParseGit recognises a default_dir entry naming a git repository: a URL with
+// an http, https, ssh, git or file scheme, an scp-style user@host:path, or a
+// path ending in .git such as a local bare repository. A "#ref" suffix pins
+// the branch or tag.
+func ParseGit(entry string) (Git, bool) {
+	entry = strings.TrimSpace(entry)
+	url, ref, _ := strings.Cut(entry, "#")
+	url = strings.TrimRight(url, "/")
+
+	isGit := scpLike.MatchString(url) || strings.HasSuffix(strings.ToLower(url), ".git")
+	if scheme, _, ok := strings.Cut(url, "://"); ok {
+		switch strings.ToLower(scheme) {
+		case "http", "https", "ssh", "git", "file":
+			isGit = true
+		}
+	}
+	if !isGit || url == "" {
+		return Git{}, false
+	}
+	return Git{URL: url, Ref: strings.TrimSpace(ref)}, true
+}
+
+// String returns the entry as written in settings.
+func (g Git) String() string {
+	if g.Ref == "" {
+		return g.URL
+	}
+	return g.URL + "#" + g.Ref
+}
+
+// Dir returns the checkout directory below cacheDir. It is derived from the
+// URL and ref, so each pin gets its own checkout.
+func (g Git) Dir(cacheDir string) string {
+	name := path.Base(filepath.ToSlash(strings.TrimSuffix(g.URL, ".git")))
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" || name == "." || name == "/" {
+		name = "repo"
+	}
+	sum := sha256.Sum256([]byte(g.String()))
+	return filepath.Join(cacheDir, "git", name+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// Sync clones the repository into its checkout directory, or updates an
+// existing checkout to the latest commit of the pinned ref. Local changes to
+// the checkout are discarded.
+func (g Git) Sync(ctx context.Context, cacheDir string) error {
+	dir := g.Dir(cacheDir)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return g.pull(ctx, dir)
+	}
+	return g.clone(ctx, dir)
+}
+
+func (g Git) clone(ctx context.Context, dir string) error {
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return err
+	}
+	// Clone next to the destination so a failed clone leaves nothing behind.
+	tmp, err := os.MkdirTemp(parent, ".clone-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if g.Ref != "" {
+		args = append(args, "--branch", g.Ref)
+	}
+	if err := runGit(ctx, "", append(args, "--", g.URL, tmp)...); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+func (g Git) pull(ctx context.Context, dir string) error {
+	ref := g.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if err := runGit(ctx, dir, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+		return err
+	}
+	return runGit(ctx, dir, "reset", "--quiet", "--hard", "FETCH_HEAD")
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never wait on a credential prompt; sync runs unattended.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return errors.New("git is not installed")
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
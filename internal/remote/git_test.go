@@ -0,0 +1,131 @@
+package remote
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a bare repository with one commit on main and returns its
+// path and a function that commits a file to it.
+func gitRepo(t *testing.T) (string, func(name, content string, tag string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	bare := filepath.Join(root, "prompts.git")
+	work := filepath.Join(root, "work")
+
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=pm", "-c", "user.email=pm@example.com", "-c", "init.defaultBranch=main"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git(root, "init", "--quiet", "--bare", bare)
+	git(root, "init", "--quiet", work)
+
+	commit := func(name, content, tag string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(work, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		git(work, "add", name)
+		git(work, "commit", "--quiet", "-m", "update "+name)
+		if tag != "" {
+			git(work, "tag", tag)
+		}
+		git(work, "push", "--quiet", "--tags", bare, "HEAD:refs/heads/main")
+	}
+	commit("review.md", "Review v1\n", "v1")
+	return bare, commit
+}
+
+func TestParseGit(t *testing.T) {
+	tests := map[string]Git{
+		"https://github.com/team/prompts.git":  {URL: "https://github.com/team/prompts.git"},
+		"https://github.com/team/prompts#v1.2": {URL: "https://github.com/team/prompts", Ref: "v1.2"},
+		"git@github.com:team/prompts.git#main": {URL: "git@github.com:team/prompts.git", Ref: "main"},
+		"file:///srv/prompts":                  {URL: "file:///srv/prompts"},
+		"/srv/git/prompts.git/":                {URL: "/srv/git/prompts.git"},
+	}
+	for entry, want := range tests {
+		if got, ok := ParseGit(entry); !ok || got != want {
+			t.Errorf("ParseGit(%q) = %+v, %v; want %+v", entry, got, ok, want)
+		}
+	}
+	for _, entry := range []string{"~/prompts", "./prompts", "/srv/prompts", "C:\\prompts"} {
+		if _, ok := ParseGit(entry); ok {
+			t.Errorf("ParseGit(%q) should not be a git source", entry)
+		}
+	}
+
+	a, _ := ParseGit("https://github.com/team/prompts.git#v1")
+	b, _ := ParseGit("https://github.com/team/prompts.git#v2")
+	if a.Dir("/cache") == b.Dir("/cache") || !strings.HasPrefix(filepath.Base(a.Dir("/cache")), "prompts-") {
+		t.Errorf("expected distinct checkouts per ref, got %s and %s", a.Dir("/cache"), b.Dir("/cache"))
+	}
+}
+
+func TestGitSyncClonesAndUpdates(t *testing.T) {
+	bare, commit := gitRepo(t)
+	cache := t.TempDir()
+	ctx := context.Background()
+
+	for _, url := range []string{bare, "file://" + filepath.ToSlash(bare)} {
+		repo := Git{URL: url}
+		if err := repo.Sync(ctx, cache); err != nil {
+			t.Fatalf("Sync(%s) error = %v", url, err)
+		}
+		assertFile(t, filepath.Join(repo.Dir(cache), "review.md"), "Review v1\n")
+	}
+
+	branch := Git{URL: bare, Ref: "main"}
+	pinned := Git{URL: bare, Ref: "v1"}
+	for _, repo := range []Git{branch, pinned} {
+		if err := repo.Sync(ctx, cache); err != nil {
+			t.Fatalf("Sync(%s) error = %v", repo, err)
+		}
+	}
+
+	commit("review.md", "Review v2\n", "")
+	// Local edits are discarded on sync.
+	if err := os.WriteFile(filepath.Join(branch.Dir(cache), "review.md"), []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []Git{branch, pinned} {
+		if err := repo.Sync(ctx, cache); err != nil {
+			t.Fatalf("Sync(%s) error = %v", repo, err)
+		}
+	}
+	assertFile(t, filepath.Join(branch.Dir(cache), "review.md"), "Review v2\n")
+	assertFile(t, filepath.Join(pinned.Dir(cache), "review.md"), "Review v1\n")
+}
+
+func TestGitSyncReportsErrors(t *testing.T) {
+	cache := t.TempDir()
+	repo := Git{URL: filepath.Join(t.TempDir(), "missing.git")}
+	if err := repo.Sync(context.Background(), cache); err == nil || !strings.Contains(err.Error(), "git clone") {
+		t.Fatalf("expected a clone error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(cache, "git")); len(entries) != 0 {
+		t.Fatalf("expected a failed clone to leave nothing behind, got %v", entries)
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != want {
+		t.Fatalf("%s = %q, want %q", path, data, want)
+	}
+}
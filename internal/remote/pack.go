@@ -0,0 +1,361 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+)
+
+// Limits guarding against oversized downloads and archive bombs.
+const (
+	maxPackSize     = 64 << 20
+	maxUnpackedSize = 256 << 20
+)
+
+var packNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Pack is a prompt pack published over HTTP as a gzipped tarball or a JSON
+// index of prompts, such as the output of `pm export`. Sync downloads it into
+// the cache, where it stays usable offline.
+type Pack struct {
+	Name string
+	URL  string
+	// SHA256 is the expected hex digest of the download.
+	SHA256 string
+	// PublicKey verifies a signature fetched from SignatureURL, which
+	// defaults to URL + ".minisig".
+	PublicKey    string
+	SignatureURL string
+	Client       *http.Client
+}
+
+// packMeta records the last successful download for conditional requests.
+type packMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// Fingerprint changes with the pack's settings, so a new digest or key
+	// forces a full download.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Dir returns the directory holding the pack's prompts.
+func (p Pack) Dir(cacheDir string) string {
+	return filepath.Join(p.base(cacheDir), "prompts")
+}
+
+// Verified reports whether downloads are checked against a digest or a
+// signature.
+func (p Pack) Verified() bool {
+	return p.SHA256 != "" || p.PublicKey != ""
+}
+
+func (p Pack) base(cacheDir string) string {
+	return filepath.Join(cacheDir, "packs", p.Name)
+}
+
+func (p Pack) fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{p.URL, p.SHA256, p.PublicKey, p.SignatureURL}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sync downloads the pack when it changed since the last sync, verifies it
+// and replaces the cached prompts. It reports whether anything was updated.
+// A failed download or verification leaves the cached copy untouched.
+func (p Pack) Sync(ctx context.Context, cacheDir string) (bool, error) {
+	if !packNamePattern.MatchString(p.Name) {
+		return false, fmt.Errorf("invalid pack name %q", p.Name)
+	}
+	if p.URL == "" {
+		return false, fmt.Errorf("pack %s has no url", p.Name)
+	}
+
+	base := p.base(cacheDir)
+	metaPath := filepath.Join(base, "pack.json")
+	var meta packMeta
+	if data, err := os.ReadFile(metaPath); err == nil {
+		_ = json.Unmarshal(data, &meta)
+	}
+	_, statErr := os.Stat(p.Dir(cacheDir))
+	cached := statErr == nil && meta.Fingerprint == p.fingerprint()
+
+	header := http.Header{}
+	if cached && meta.ETag != "" {
+		header.Set("If-None-Match", meta.ETag)
+	}
+	if cached && meta.LastModified != "" {
+		header.Set("If-Modified-Since", meta.LastModified)
+	}
+	resp, err := p.get(ctx, p.URL, header)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && cached {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("fetch %s: %s", p.URL, resp.Status)
+	}
+
+	body, err := readLimited(resp.Body, maxPackSize)
+	if err != nil {
+		return false, fmt.Errorf("fetch %s: %w", p.URL, err)
+	}
+	if err := p.verify(ctx, body); err != nil {
+		return false, fmt.Errorf("verify pack %s: %w", p.Name, err)
+	}
+
+	if err := os.MkdirAll(base, 0o755); err != nil {
+		return false, err
+	}
+	tmp, err := os.MkdirTemp(base, ".sync-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.Chmod(tmp, 0o755); err != nil {
+		return false, err
+	}
+	if err := unpack(body, tmp); err != nil {
+		return false, fmt.Errorf("unpack pack %s: %w", p.Name, err)
+	}
+	if err := os.RemoveAll(p.Dir(cacheDir)); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, p.Dir(cacheDir)); err != nil {
+		return false, err
+	}
+
+	meta = packMeta{
+		URL:          p.URL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Fingerprint:  p.fingerprint(),
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return true, err
+	}
+	return true, os.WriteFile(metaPath, data, 0o644)
+}
+
+func (p Pack) get(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func (p Pack) verify(ctx context.Context, body []byte) error {
+	if want := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(p.SHA256)), "sha256:"); want != "" {
+		sum := sha256.Sum256(body)
+		if got := hex.EncodeToString(sum[:]); got != want {
+			return fmt.Errorf("sha256 is %s, want %s", got, want)
+		}
+	}
+	if p.PublicKey == "" {
+		return nil
+	}
+
+	sigURL := p.SignatureURL
+	if sigURL == "" {
+		sigURL = p.URL + ".minisig"
+	}
+	resp, err := p.get(ctx, sigURL, http.Header{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch signature %s: %s", sigURL, resp.Status)
+	}
+	sig, err := readLimited(resp.Body, 64<<10)
+	if err != nil {
+		return fmt.Errorf("fetch signature %s: %w", sigURL, err)
+	}
+	return verifySignature(p.PublicKey, sig, body)
+}
+
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// unpack writes the prompts of a tarball or JSON index into dir.
+func unpack(body []byte, dir string) error {
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		return untar(body, dir)
+	}
+	if trimmed := bytes.TrimSpace(body); bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("{")) {
+		return writeIndex(trimmed, dir)
+	}
+	return errors.New("not a gzipped tarball or JSON index")
+}
+
+// untar extracts regular files and directories, rejecting paths that would
+// escape dir. Links and other entries are skipped.
+func untar(body []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	var total int64
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(strings.TrimPrefix(header.Name, "./"))
+		if name == "" || name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("unsafe path %q", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			total += header.Size
+			if total > maxUnpackedSize {
+				return fmt.Errorf("unpacked size exceeds %d bytes", maxUnpackedSize)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, io.LimitReader(tr, header.Size))
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// indexEntry is a prompt in a JSON index, as written by `pm export`.
+type indexEntry struct {
+	Name            string           `json:"name"`
+	Content         string           `json:"content"`
+	FrontMatter     map[string]any   `json:"front_matter"`
+	Messages        []prompt.Message `json:"messages"`
+	Model           string           `json:"model"`
+	ModelParameters map[string]any   `json:"model_parameters"`
+	Inputs          []prompt.Input   `json:"inputs"`
+}
+
+// writeIndex turns a JSON index, either an array of prompts or an object
+// with a "prompts" array, into prompt files. Chat prompts become structured
+// .prompt.json files and the rest Markdown with front matter. When names
+// repeat, the first entry wins.
+func writeIndex(data []byte, dir string) error {
+	var entries []indexEntry
+	if bytes.HasPrefix(data, []byte("{")) {
+		var index struct {
+			Prompts []indexEntry `json:"prompts"`
+		}
+		if err := json.Unmarshal(data, &index); err != nil {
+			return err
+		}
+		entries = index.Prompts
+	} else if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	written := make(map[string]bool)
+	for _, entry := range entries {
+		name := strings.TrimSpace(entry.Name)
+		if name == "" || !filepath.IsLocal(name) || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+			return fmt.Errorf("invalid prompt name %q", entry.Name)
+		}
+		if written[strings.ToLower(name)] {
+			continue
+		}
+		written[strings.ToLower(name)] = true
+
+		var file string
+		var content []byte
+		if len(entry.Messages) > 0 {
+			doc := make(map[string]any, len(entry.FrontMatter)+4)
+			for key, value := range entry.FrontMatter {
+				doc[key] = value
+			}
+			doc["messages"] = entry.Messages
+			if entry.Model != "" {
+				doc["model"] = entry.Model
+			}
+			if len(entry.ModelParameters) > 0 {
+				doc["modelParameters"] = entry.ModelParameters
+			}
+			if len(entry.Inputs) > 0 {
+				doc["variables"] = entry.Inputs
+			}
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return err
+			}
+			file, content = name+prompt.StructuredJSONSuffix, append(data, '\n')
+		} else {
+			var b bytes.Buffer
+			if len(entry.FrontMatter) > 0 {
+				front, err := yaml.Marshal(entry.FrontMatter)
+				if err != nil {
+					return err
+				}
+				b.WriteString("---\n")
+				b.Write(front)
+				b.WriteString("---\n\n")
+			}
+			b.WriteString(strings.TrimRight(entry.Content, "\r\n"))
+			b.WriteString("\n")
+			file, content = name+".md", b.Bytes()
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hzionn/prompt-manager-cli/internal/prompt"
+	"golang.org/x/crypto/blake2b"
+)
+
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// packServer serves files by path and answers conditional requests for
+// the ETag "v1".
+func packServer(t *testing.T, files map[string][]byte) (*httptest.Server, *int) {
+	t.Helper()
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestPackSyncTarballWithChecksum(t *testing.T) {
+	body := tarball(t, map[string]string{"team/review.md": "Review\n", "./standup.md": "Standup\n"})
+	sum := sha256.Sum256(body)
+	server, downloads := packServer(t, map[string][]byte{"/pack.tar.gz": body})
+	cache := t.TempDir()
+	ctx := context.Background()
+
+	pack := Pack{Name: "team", URL: server.URL + "/pack.tar.gz", SHA256: hex.EncodeToString(sum[:])}
+	updated, err := pack.Sync(ctx, cache)
+	if err != nil || !updated {
+		t.Fatalf("Sync() = %v, %v; want an update", updated, err)
+	}
+	assertFile(t, filepath.Join(pack.Dir(cache), "team", "review.md"), "Review\n")
+	assertFile(t, filepath.Join(pack.Dir(cache), "standup.md"), "Standup\n")
+
+	updated, err = pack.Sync(ctx, cache)
+	if err != nil || updated || *downloads != 1 {
+		t.Fatalf("expected a conditional request to keep the cache, got %v, %v after %d downloads", updated, err, *downloads)
+	}
+
+	pack.SHA256 = strings.Repeat("0", 64)
+	if _, err := pack.Sync(ctx, cache); err == nil || !strings.Contains(err.Error(), "sha256") {
+		t.Fatalf("expected a checksum error, got %v", err)
+	}
+	assertFile(t, filepath.Join(pack.Dir(cache), "standup.md"), "Standup\n")
+}
+
+func minisignKey(t *testing.T) (string, func(data []byte, alg string) []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte("pmkeyid1")
+	publicKey := "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...)) + "\n"
+
+	sign := func(data []byte, alg string) []byte {
+		signed := data
+		if alg == "ED" {
+			digest := blake2b.Sum512(data)
+			signed = digest[:]
+		}
+		signature := ed25519.Sign(priv, signed)
+		comment := "timestamp:1700000000"
+		global := ed25519.Sign(priv, append(append([]byte(nil), signature...), comment...))
+		return []byte("untrusted comment: signature\n" +
+			base64.StdEncoding.EncodeToString(append(append([]byte(alg), keyID...), signature...)) + "\n" +
+			trustedCommentPrefix + comment + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n")
+	}
+	return publicKey, sign
+}
+
+func TestPackSyncSignedJSONIndex(t *testing.T) {
+	index := []byte(`{"prompts": [
+		{"name": "review", "content": "Review this.\n", "front_matter": {"tags": ["team"]}},
+		{"name": "translate", "content": "flattened", "messages": [{"role": "system", "content": "Translate."}, {"role": "user", "content": "{{text}}"}], "model": "small"},
+		{"name": "review", "content": "Shadowed"}
+	]}`)
+	publicKey, sign := minisignKey(t)
+	server, _ := packServer(t, map[string][]byte{
+		"/index.json":             index,
+		"/index.json.minisig":     sign(index, "Ed"),
+		"/tampered.json":          append(index, ' '),
+		"/tampered.json.minisig":  sign(index, "Ed"),
+		"/prehashed.json":         index,
+		"/prehashed.json.minisig": sign(index, "ED"),
+		"/rehashed.json":          append(index, ' '),
+		"/rehashed.json.minisig":  sign(index, "ED"),
+	})
+	cache := t.TempDir()
+	ctx := context.Background()
+
+	pack := Pack{Name: "oss", URL: server.URL + "/index.json", PublicKey: publicKey}
+	if _, err := pack.Sync(ctx, cache); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	prompts, err := prompt.LoadFromDirs([]string{pack.Dir(cache)}, prompt.Options{Extensions: []string{".md", ".prompt.json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompts) != 2 || prompts[0].Name != "review" || !prompts[0].HasTag("team") || prompts[0].Content != "Review this.\n" {
+		t.Fatalf("unexpected prompts %+v", prompts)
+	}
+	if prompts[1].Name != "translate" || len(prompts[1].Messages) != 2 || prompts[1].Model != "small" {
+		t.Fatalf("expected the structured prompt to keep its messages, got %+v", prompts[1])
+	}
+
+	tampered := Pack{Name: "tampered", URL: server.URL + "/tampered.json", PublicKey: publicKey}
+	if _, err := tampered.Sync(ctx, cache); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected a signature error, got %v", err)
+	}
+	if _, err := os.Stat(tampered.Dir(cache)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected nothing cached for a rejected pack, got %v", err)
+	}
+
+	prehashed := Pack{Name: "prehashed", URL: server.URL + "/prehashed.json", PublicKey: publicKey}
+	if _, err := prehashed.Sync(ctx, cache); err != nil {
+		t.Fatalf("expected the prehashed signature to verify, got %v", err)
+	}
+	rehashed := Pack{Name: "rehashed", URL: server.URL + "/rehashed.json", PublicKey: publicKey}
+	if _, err := rehashed.Sync(ctx, cache); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected a prehashed signature error for changed data, got %v", err)
+	}
+}
+
+func TestVerifySignatureWithBareEd25519Key(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("pack contents")
+	key := base64.StdEncoding.EncodeToString(pub)
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)) + "\n")
+
+	if err := verifySignature(key, sig, data); err != nil {
+		t.Fatalf("verifySignature() error = %v", err)
+	}
+	if err := verifySignature(key, sig, []byte("other contents")); err == nil {
+		t.Fatal("expected a mismatch for other data")
+	}
+}
+
+func TestPackSyncRejectsUnsafeArchives(t *testing.T) {
+	server, _ := packServer(t, map[string][]byte{
+		"/escape.tar.gz": tarball(t, map[string]string{"../outside.md": "x"}),
+		"/plain.txt":     []byte("just text"),
+	})
+	cache := t.TempDir()
+	for _, pack := range []Pack{
+		{Name: "escape", URL: server.URL + "/escape.tar.gz"},
+		{Name: "plain", URL: server.URL + "/plain.txt"},
+		{Name: "../evil", URL: server.URL + "/plain.txt"},
+		{Name: "missing", URL: server.URL + "/missing.json"},
+	} {
+		if _, err := pack.Sync(context.Background(), cache); err == nil {
+			t.Errorf("expected pack %s to fail", pack.Name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cache, "packs", "outside.md")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no file outside the pack, got %v", err)
+	}
+}
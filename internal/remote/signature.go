@@ -0,0 +1,109 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const trustedCommentPrefix = "trusted comment: "
+
+// verifySignature checks sig over data with publicKey. The key is a minisign
+// public key (the key line or the whole .pub file) or a base64 ed25519 key;
+// the signature is a minisign signature file, prehashed (the default) or
+// legacy, or a base64 ed25519 signature.
+func verifySignature(publicKey string, sig, data []byte) error {
+	key, keyID, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	lines := nonEmptyLines(string(sig))
+	if len(lines) == 0 {
+		return errors.New("empty signature")
+	}
+	if !strings.HasPrefix(lines[0], "untrusted comment:") {
+		raw, err := base64.StdEncoding.DecodeString(lines[0])
+		if err != nil || len(raw) != ed25519.SignatureSize {
+			return errors.New("malformed signature")
+		}
+		if !ed25519.Verify(key, data, raw) {
+			return errors.New("signature does not match")
+		}
+		return nil
+	}
+
+	if len(lines) < 2 {
+		return errors.New("malformed minisign signature")
+	}
+	blob, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(blob) != 2+8+ed25519.SignatureSize {
+		return errors.New("malformed minisign signature")
+	}
+	signed := data
+	switch string(blob[:2]) {
+	case "Ed":
+	case "ED":
+		// Prehashed signatures cover the BLAKE2b-512 digest of the data.
+		digest := blake2b.Sum512(data)
+		signed = digest[:]
+	default:
+		return fmt.Errorf("unknown minisign signature algorithm %q", blob[:2])
+	}
+	if keyID != nil && !bytes.Equal(blob[2:10], keyID) {
+		return errors.New("signature was made with a different key")
+	}
+	signature := blob[10:]
+	if !ed25519.Verify(key, signed, signature) {
+		return errors.New("signature does not match")
+	}
+
+	// The trusted comment is signed together with the signature.
+	if len(lines) >= 4 && strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		global, err := base64.StdEncoding.DecodeString(lines[3])
+		if err != nil || len(global) != ed25519.SignatureSize {
+			return errors.New("malformed minisign trusted comment signature")
+		}
+		comment := strings.TrimPrefix(lines[2], trustedCommentPrefix)
+		if !ed25519.Verify(key, append(append([]byte(nil), signature...), comment...), global) {
+			return errors.New("trusted comment signature does not match")
+		}
+	}
+	return nil
+}
+
+// parsePublicKey decodes a minisign or bare ed25519 public key. The key ID
+// is nil for bare keys.
+func parsePublicKey(value string) (ed25519.PublicKey, []byte, error) {
+	lines := nonEmptyLines(value)
+	if len(lines) == 0 {
+		return nil, nil, errors.New("empty public key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[len(lines)-1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed public key: %w", err)
+	}
+	switch {
+	case len(raw) == 2+8+ed25519.PublicKeySize && string(raw[:2]) == "Ed":
+		return ed25519.PublicKey(raw[10:]), raw[2:10], nil
+	case len(raw) == ed25519.PublicKeySize:
+		return ed25519.PublicKey(raw), nil, nil
+	default:
+		return nil, nil, errors.New("public key is neither a minisign nor an ed25519 key")
+	}
+}
+
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -15,6 +15,7 @@ var normalizerReplacer = strings.NewReplacer(
 	"_", " ",
 	"-", " ",
 	"/", " ",
+	"#", " ",
 	".", " ",
 	",", " ",
 	"\n", " ",
@@ -78,12 +79,14 @@ func Search(prompts []prompt.Prompt, query string, opts Options) []prompt.Prompt
 func aggregateScore(p prompt.Prompt, rawQuery, normalizedQuery string) float64 {
 	nameScore := fuzzyScore(normalizedQuery, normalize(p.Name))
 
-	aliasScore := bestScore(valuesFromFront(p.FrontMatter, "aliases"), normalizedQuery)
+	aliasScore := bestScore(p.Aliases, normalizedQuery)
 	tagScore := bestScore(p.Tags, normalizedQuery)
 
 	metaSkip := map[string]struct{}{
 		"tags":    {},
+		"tag":     {},
 		"aliases": {},
+		"alias":   {},
 	}
 	metaScore := bestScore(collectFrontMatterStrings(p.FrontMatter, metaSkip), normalizedQuery)
 
@@ -155,17 +158,6 @@ func fuzzyScore(query, candidate string) float64 {
 	return score
 }
 
-func valuesFromFront(front map[string]any, key string) []string {
-	if front == nil {
-		return nil
-	}
-	value, ok := front[key]
-	if !ok {
-		return nil
-	}
-	return flattenValue(value)
-}
-
 func collectFrontMatterStrings(front map[string]any, skip map[string]struct{}) []string {
 	if front == nil {
 		return nil
@@ -31,6 +31,28 @@ type fd interface {
 // Options configure selector rendering.
 type Options struct {
 	TruncateLength int
+	// TargetLabel enables the Ctrl+T keybinding that hands the prompt to an
+	// external target; the label is shown in the help line.
+	TargetLabel string
+	// Updates delivers fresh prompt snapshots while the picker is open, such
+	// as those produced by a watched prompt.Library.
+	Updates <-chan []prompt.Prompt
+}
+
+// Action describes what should happen to the selected prompt.
+type Action int
+
+const (
+	// ActionOutput prints (or copies) the prompt.
+	ActionOutput Action = iota
+	// ActionSend hands the prompt to the configured target.
+	ActionSend
+)
+
+// Result is the prompt picked by the user together with the requested action.
+type Result struct {
+	Prompt prompt.Prompt
+	Action Action
 }
 
 const defaultTruncateLength = 120
@@ -49,19 +71,26 @@ func SelectPrompt(prompts []prompt.Prompt, in io.Reader, out io.Writer) (prompt.
 
 // SelectPromptWithQuery enables interactive filtering seeded with an initial query.
 func SelectPromptWithQuery(prompts []prompt.Prompt, initialQuery string, opts search.Options, uiOpts Options, in io.Reader, out io.Writer) (prompt.Prompt, error) {
+	result, err := Select(prompts, initialQuery, opts, uiOpts, in, out)
+	return result.Prompt, err
+}
+
+// Select is like SelectPromptWithQuery but also reports the action requested
+// by the user.
+func Select(prompts []prompt.Prompt, initialQuery string, opts search.Options, uiOpts Options, in io.Reader, out io.Writer) (Result, error) {
 	if len(prompts) == 0 {
-		return prompt.Prompt{}, ErrNoPrompts
+		return Result{}, ErrNoPrompts
 	}
 
 	uiOpts = normalizeOptions(uiOpts)
 
 	if isTerminal(in) && isTerminal(out) {
-		selected, err := runInteractiveSelector(prompts, initialQuery, opts, uiOpts, in, out)
+		result, err := runInteractiveSelector(prompts, initialQuery, opts, uiOpts, in, out)
 		if err == nil {
-			return selected, nil
+			return result, nil
 		}
 		if errors.Is(err, ErrInvalidSelection) {
-			return prompt.Prompt{}, err
+			return Result{}, err
 		}
 		// If the TUI fails for any other reason, fall back to the simple selector.
 	}
@@ -73,10 +102,11 @@ func SelectPromptWithQuery(prompts []prompt.Prompt, initialQuery string, opts se
 		}
 	}
 
-	return selectPromptFallback(display, in, out)
+	selected, err := selectPromptFallback(display, in, out)
+	return Result{Prompt: selected}, err
 }
 
-func runInteractiveSelector(prompts []prompt.Prompt, initialQuery string, opts search.Options, uiOpts Options, in io.Reader, out io.Writer) (prompt.Prompt, error) {
+func runInteractiveSelector(prompts []prompt.Prompt, initialQuery string, opts search.Options, uiOpts Options, in io.Reader, out io.Writer) (Result, error) {
 	model := newSelectorModel(prompts, initialQuery, opts, uiOpts)
 
 	options := []tea.ProgramOption{
@@ -90,20 +120,24 @@ func runInteractiveSelector(prompts []prompt.Prompt, initialQuery string, opts s
 	prog := tea.NewProgram(model, options...)
 	finalModel, err := prog.StartReturningModel()
 	if err != nil {
-		return prompt.Prompt{}, err
+		return Result{}, err
 	}
 
 	sel := finalModel.(*selectorModel)
 	if sel.cancelled || len(sel.filtered) == 0 {
-		return prompt.Prompt{}, ErrInvalidSelection
+		return Result{}, ErrInvalidSelection
 	}
 
-	return sel.filtered[sel.cursor], nil
+	return Result{Prompt: sel.filtered[sel.cursor], Action: sel.action}, nil
 }
 
 func selectPromptFallback(prompts []prompt.Prompt, in io.Reader, out io.Writer) (prompt.Prompt, error) {
 	fmt.Fprintln(out, "Select a prompt:")
 	for idx, p := range prompts {
+		if p.Source != "" {
+			fmt.Fprintf(out, "%d) %s (%s)\n", idx+1, p.Name, p.Source)
+			continue
+		}
 		fmt.Fprintf(out, "%d) %s\n", idx+1, p.Name)
 	}
 	fmt.Fprint(out, "> ")
@@ -162,6 +196,7 @@ type selectorModel struct {
 	filterOpts search.Options
 	uiOpts     Options
 	mode       selectorMode
+	action     Action
 }
 
 type selectorMode int
@@ -183,7 +218,55 @@ func newSelectorModel(prompts []prompt.Prompt, initialQuery string, opts search.
 }
 
 func (m *selectorModel) Init() tea.Cmd {
-	return nil
+	return m.waitForUpdates()
+}
+
+// promptsUpdatedMsg carries a new prompt snapshot from Options.Updates.
+type promptsUpdatedMsg struct {
+	prompts []prompt.Prompt
+}
+
+func (m *selectorModel) waitForUpdates() tea.Cmd {
+	updates := m.uiOpts.Updates
+	if updates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		prompts, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return promptsUpdatedMsg{prompts: prompts}
+	}
+}
+
+// replacePrompts swaps in a new prompt set, re-applying the current filter
+// and keeping the cursor on the same prompt when it still exists.
+func (m *selectorModel) replacePrompts(prompts []prompt.Prompt) {
+	var current prompt.Prompt
+	hasCurrent := len(m.filtered) > 0
+	if hasCurrent {
+		current = m.filtered[m.cursor]
+	}
+
+	m.allPrompts = append([]prompt.Prompt(nil), prompts...)
+	m.applyQuery(m.query)
+
+	if !hasCurrent {
+		return
+	}
+	for i, p := range m.filtered {
+		if samePrompt(p, current) {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// samePrompt matches on both path and name since the sections of a split file
+// share one path.
+func samePrompt(a, b prompt.Prompt) bool {
+	return a.Path == b.Path && a.Name == b.Name
 }
 
 func (m *selectorModel) toggleMode() {
@@ -215,6 +298,12 @@ func (m *selectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cancelled = true
 			}
 			return m, tea.Quit
+		case "ctrl+t":
+			if m.uiOpts.TargetLabel == "" || len(m.filtered) == 0 {
+				return m, nil
+			}
+			m.action = ActionSend
+			return m, tea.Quit
 		case "up", "ctrl+p":
 			m.moveUp()
 		case "down", "ctrl+n":
@@ -243,6 +332,9 @@ func (m *selectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
+	case promptsUpdatedMsg:
+		m.replacePrompts(msg.prompts)
+		return m, m.waitForUpdates()
 	}
 
 	return m, nil
@@ -362,10 +454,14 @@ func (m *selectorModel) View() string {
 	var b strings.Builder
 	b.WriteString("\n Filter: " + m.query + "\n")
 	if m.mode == modeFilter {
-		b.WriteString(" Typing mode (Esc to switch to navigation). ↑/↓ move, Enter confirms, Ctrl+C cancels\n\n")
+		b.WriteString(" Typing mode (Esc to switch to navigation). ↑/↓ move, Enter confirms, Ctrl+C cancels\n")
 	} else {
-		b.WriteString(" Navigation mode (Esc to switch to typing). ↑/↓/j/k move, Enter confirms, Ctrl+C cancels\n\n")
+		b.WriteString(" Navigation mode (Esc to switch to typing). ↑/↓/j/k move, Enter confirms, Ctrl+C cancels\n")
 	}
+	if m.uiOpts.TargetLabel != "" {
+		b.WriteString(" Ctrl+T sends to " + m.uiOpts.TargetLabel + "\n")
+	}
+	b.WriteByte('\n')
 
 	if len(m.filtered) == 0 {
 		b.WriteString("  No matches. Keep typing or press Esc to cancel.\n")
@@ -436,6 +532,9 @@ func renderPromptTitle(p prompt.Prompt, width int, truncateLength int) string {
 		limit = truncateLength
 	}
 	name := truncate(p.Name, limit)
+	if p.Source != "" {
+		name = truncate(fmt.Sprintf("%s  (%s)", name, p.Source), limit)
+	}
 	if len(p.Tags) == 0 {
 		return name
 	}
@@ -449,7 +548,7 @@ func renderPreview(p prompt.Prompt, width int, truncateLength int) string {
 
 	var sections []string
 
-	if summary := frontMatterString(p.FrontMatter, "summary"); summary != "" {
+	if summary := p.FrontMatterString("summary"); summary != "" {
 		sections = append(sections, "Summary:\n"+indent(wrap(limitText(summary, truncateLength), width), "  "))
 	}
 
@@ -464,32 +563,6 @@ func renderPreview(p prompt.Prompt, width int, truncateLength int) string {
 	return strings.Join(sections, "\n\n")
 }
 
-func frontMatterString(front map[string]any, key string) string {
-	if front == nil {
-		return ""
-	}
-
-	value, ok := front[key]
-	if !ok || value == nil {
-		return ""
-	}
-
-	switch v := value.(type) {
-	case string:
-		return strings.TrimSpace(v)
-	case fmt.Stringer:
-		return strings.TrimSpace(v.String())
-	case []any:
-		var parts []string
-		for _, item := range v {
-			parts = append(parts, fmt.Sprint(item))
-		}
-		return strings.Join(parts, ", ")
-	default:
-		return fmt.Sprint(v)
-	}
-}
-
 func wrap(text string, limit int) string {
 	if limit <= 0 {
 		return text
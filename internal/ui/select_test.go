@@ -268,6 +268,30 @@ func TestSelectorModelInitialQuery(t *testing.T) {
 	}
 }
 
+func TestSelectorModelShowsSourceLabel(t *testing.T) {
+	prompts := []prompt.Prompt{
+		{Name: "review", Source: "team", Tags: []string{"code"}},
+		{Name: "notes"},
+	}
+
+	model := newSelectorModel(prompts, "", search.Options{}, Options{})
+	view := model.View()
+	if strings.Contains(view, "notes  (") {
+		t.Fatalf("expected unlabelled prompt without a source, got %q", view)
+	}
+	if !strings.Contains(view, "review  (team)  [code]") {
+		t.Fatalf("expected source label in the list, got %q", view)
+	}
+
+	var output bytes.Buffer
+	if _, err := SelectPrompt(prompts, strings.NewReader("1\n"), &output); err != nil {
+		t.Fatalf("SelectPrompt() error = %v", err)
+	}
+	if !strings.Contains(output.String(), "1) review (team)\n") {
+		t.Fatalf("expected source label in the fallback list, got %q", output.String())
+	}
+}
+
 func assertPromptNames(t *testing.T, prompts []prompt.Prompt, want []string) {
 	t.Helper()
 	if len(prompts) != len(want) {
@@ -279,3 +303,104 @@ func assertPromptNames(t *testing.T, prompts []prompt.Prompt, want []string) {
 		}
 	}
 }
+
+func TestSelectorModelSendsToTarget(t *testing.T) {
+	prompts := []prompt.Prompt{{Name: "alpha"}, {Name: "beta"}}
+
+	model := newSelectorModel(prompts, "", search.Options{}, Options{})
+	next, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	model = next.(*selectorModel)
+	if cmd != nil || model.action != ActionOutput {
+		t.Fatal("expected ctrl+t to be ignored without a target")
+	}
+
+	model = newSelectorModel(prompts, "", search.Options{}, Options{TargetLabel: "codex"})
+	if !strings.Contains(model.View(), "Ctrl+T sends to codex") {
+		t.Fatalf("expected target hint in view, got %q", model.View())
+	}
+
+	next, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = next.(*selectorModel)
+	next, cmd = model.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	model = next.(*selectorModel)
+	if cmd == nil || model.action != ActionSend {
+		t.Fatal("expected ctrl+t to quit with ActionSend")
+	}
+	if model.filtered[model.cursor].Name != "beta" {
+		t.Fatalf("expected beta to be selected, got %s", model.filtered[model.cursor].Name)
+	}
+}
+
+func TestSelectorModelReloadsPrompts(t *testing.T) {
+	prompts := []prompt.Prompt{
+		{Name: "alpha", Path: "/p/alpha.md", Content: "old"},
+		{Name: "beta", Path: "/p/beta.md"},
+		{Name: "gamma", Path: "/p/gamma.md"},
+	}
+	updates := make(chan []prompt.Prompt, 1)
+
+	model := newSelectorModel(prompts, "", search.Options{}, Options{Updates: updates})
+	next, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = next.(*selectorModel)
+	next, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = next.(*selectorModel)
+
+	cmd := model.Init()
+	if cmd == nil {
+		t.Fatal("expected Init to wait for updates")
+	}
+	updates <- []prompt.Prompt{
+		{Name: "aardvark", Path: "/p/aardvark.md"},
+		{Name: "alpha", Path: "/p/alpha.md", Content: "new"},
+		{Name: "gamma", Path: "/p/gamma.md"},
+	}
+	next, cmd = model.Update(cmd())
+	model = next.(*selectorModel)
+	if cmd == nil {
+		t.Fatal("expected the model to keep waiting for updates")
+	}
+	if got := model.filtered[model.cursor].Name; got != "gamma" {
+		t.Fatalf("expected cursor to stay on gamma, got %s", got)
+	}
+
+	model.applyQuery("alp")
+	updates <- []prompt.Prompt{{Name: "alpha", Path: "/p/alpha.md", Content: "newer"}}
+	next, _ = model.Update(cmd())
+	model = next.(*selectorModel)
+	if model.query != "alp" || len(model.filtered) != 1 || model.filtered[0].Content != "newer" {
+		t.Fatalf("expected filter to be re-applied to the new prompts, got %+v", model.filtered)
+	}
+
+	close(updates)
+	if msg := cmd(); msg != nil {
+		t.Fatalf("expected no message after updates close, got %#v", msg)
+	}
+}
+
+func TestSelectorModelReloadKeepsSplitSection(t *testing.T) {
+	prompts := []prompt.Prompt{
+		{Name: "guide#Intro", Path: "/p/guide.md"},
+		{Name: "guide#Usage", Path: "/p/guide.md"},
+		{Name: "guide#Notes", Path: "/p/guide.md"},
+	}
+	updates := make(chan []prompt.Prompt, 1)
+
+	model := newSelectorModel(prompts, "", search.Options{}, Options{Updates: updates})
+	next, _ := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = next.(*selectorModel)
+	next, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model = next.(*selectorModel)
+	want := model.filtered[model.cursor].Name
+
+	updates <- []prompt.Prompt{
+		{Name: "guide#Intro", Path: "/p/guide.md"},
+		{Name: "guide#Setup", Path: "/p/guide.md"},
+		{Name: "guide#Usage", Path: "/p/guide.md"},
+		{Name: "guide#Notes", Path: "/p/guide.md"},
+	}
+	next, _ = model.Update(model.Init()())
+	model = next.(*selectorModel)
+	if got := model.filtered[model.cursor].Name; got != want {
+		t.Fatalf("expected cursor to stay on %s, got %s", want, got)
+	}
+}